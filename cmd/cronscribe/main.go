@@ -0,0 +1,40 @@
+// Command cronscribe provides tooling around cronscribe rule files
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: cronscribe <command> [arguments]
+
+Commands:
+  rules test      run the examples of every rule across all languages
+  rules analyze   report overlapping rules, unused dictionary entries and variables
+  mine            propose rules from recorded AI fallbacks (JSON lines files or stdin)
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches a command and returns the process exit code
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "rules":
+		return runRules(args[1:])
+	case "mine":
+		return runMine(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", args[0], usage)
+		return 2
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+	R "github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// runMine reads AI fallback records and prints candidate rules as YAML
+func runMine(args []string) int {
+	fs := flag.NewFlagSet("mine", flag.ContinueOnError)
+	dir := fs.String("dir", "pkg/core/rules", "directory with rule files")
+	lang := fs.String("lang", "en", "language of records that have none")
+	minSupport := fs.Int("min-support", 2, "minimum number of records behind a candidate")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	allRules, err := R.LoadAllRules(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	records, err := readRecordFiles(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	observations := make([]R.Observation, 0, len(records))
+	for _, record := range records {
+		language := record.Language
+		if language == "" {
+			language = *lang
+		}
+		observations = append(observations, R.Observation{Input: record.Input, Language: language, Cron: record.Expression})
+	}
+
+	candidates := R.Mine(observations, allRules, *minSupport)
+	fmt.Fprintf(os.Stderr, "%d records, %d candidate rules\n", len(records), len(candidates))
+
+	language := ""
+	for _, c := range candidates {
+		if c.Language != language {
+			if language != "" {
+				fmt.Println("---")
+			}
+			language = c.Language
+			fmt.Printf("# candidate rules, review before adding them to %s.yaml\nlanguage: %s\nrules:\n", language, language)
+		}
+
+		data, err := yaml.Marshal([]R.Rule{c.Rule})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("  # support: %d records\n", c.Support)
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			fmt.Println("  " + line)
+		}
+	}
+
+	return 0
+}
+
+// readRecordFiles reads records from the given files, or from stdin when there are none
+func readRecordFiles(files []string) ([]ai.Record, error) {
+	if len(files) == 0 {
+		return ai.ReadRecords(os.Stdin)
+	}
+
+	var records []ai.Record
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		read, err := ai.ReadRecords(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		records = append(records, read...)
+	}
+	return records, nil
+}
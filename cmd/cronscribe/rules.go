@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	R "github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// runRules dispatches the rules subcommands
+func runRules(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "test":
+		return runRulesTest(args[1:])
+	case "analyze":
+		return runRulesAnalyze(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown rules command: %s\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+// runRulesTest runs the examples of all rule files in a directory
+func runRulesTest(args []string) int {
+	fs := flag.NewFlagSet("rules test", flag.ContinueOnError)
+	dir := fs.String("dir", "pkg/core/rules", "directory with rule files")
+	verbose := fs.Bool("v", false, "print passing examples too")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	allRules, err := R.LoadAllRules(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	var total, failed int
+	for _, lang := range sortedLanguages(allRules) {
+		for _, result := range R.RunExamples(allRules[lang]) {
+			total++
+			if result.Passed {
+				if *verbose {
+					fmt.Printf("ok   %s/%s: %q\n", result.Language, result.Rule, result.Example.Input)
+				}
+				continue
+			}
+
+			failed++
+			fmt.Printf("FAIL %s/%s: %q\n%s", result.Language, result.Rule, result.Example.Input, result.Diff())
+		}
+	}
+
+	fmt.Printf("%d examples, %d failed\n", total, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runRulesAnalyze prints a JSON report on overlapping rules, unused
+// dictionary entries and unused variables for every language
+func runRulesAnalyze(args []string) int {
+	fs := flag.NewFlagSet("rules analyze", flag.ContinueOnError)
+	dir := fs.String("dir", "pkg/core/rules", "directory with rule files")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	allRules, err := R.LoadAllRules(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	reports := make([]R.Report, 0, len(allRules))
+	shadowing := false
+	for _, lang := range sortedLanguages(allRules) {
+		report := R.Analyze(allRules[lang])
+		shadowing = shadowing || report.HasShadowing()
+		reports = append(reports, report)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(reports); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if shadowing {
+		return 1
+	}
+	return 0
+}
+
+// sortedLanguages returns the languages of the rule sets in a stable order
+func sortedLanguages(allRules map[string]*R.Rules) []string {
+	languages := make([]string, 0, len(allRules))
+	for lang := range allRules {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
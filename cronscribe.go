@@ -10,8 +10,8 @@ type CronScribe struct {
 }
 
 // New creates a new instance of CronScribe with the default rule-based mapper
-func New(rulesDir string) (*CronScribe, error) {
-	c, err := core.New(rulesDir)
+func New(rulesDir string, options ...core.Option) (*CronScribe, error) {
+	c, err := core.New(rulesDir, options...)
 	if err != nil {
 		return nil, err
 	}
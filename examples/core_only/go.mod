@@ -4,4 +4,6 @@ go 1.24.0
 
 require github.com/flaticols/cronscribe/pkg/core v0.0.0
 
-replace github.com/flaticols/cronscribe/pkg/core => ../../pkg/core
\ No newline at end of file
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace github.com/flaticols/cronscribe/pkg/core => ../../pkg/core
@@ -8,7 +8,14 @@ require (
 	github.com/tmc/langchaingo v0.1.13
 )
 
+require (
+	github.com/dlclark/regexp2 v1.10.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pkoukk/tiktoken-go v0.1.6 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
 replace (
 	github.com/flaticols/cronscribe/pkg/ai => ../../pkg/ai
 	github.com/flaticols/cronscribe/pkg/core => ../../pkg/core
-)
\ No newline at end of file
+)
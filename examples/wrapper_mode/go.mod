@@ -2,8 +2,8 @@ module github.com/flaticols/cronscribe/examples/wrapper_mode
 
 go 1.24.0
 
-require (
-	github.com/flaticols/cronscribe v0.0.0
-)
+require github.com/flaticols/cronscribe v0.0.0
 
-replace github.com/flaticols/cronscribe => ../..
\ No newline at end of file
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace github.com/flaticols/cronscribe => ../..
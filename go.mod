@@ -6,12 +6,12 @@ require (
 	github.com/flaticols/cronscribe/pkg/ai v0.0.0
 	github.com/flaticols/cronscribe/pkg/core v0.0.0
 	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace (
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 )
 
 // AIProvider is an interface for services that can generate cron expressions from human text
@@ -17,8 +18,9 @@ func RecommendedSystemPrompt() string {
 
 // RecommendedUserPrompt formats input text into a recommended user prompt
 func RecommendedUserPrompt(input string) string {
+	quoted, _ := json.Marshal(input)
 	return `Convert the following human-readable schedule description to a cron expression:
-"` + input + `"
+` + string(quoted) + `
 
 The response should be ONLY the valid cron expression in the standard 5-field format (minute hour day-of-month month day-of-week).
 Do not include any explanations or additional text.`
@@ -0,0 +1,231 @@
+package aitest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+)
+
+// ErrNotRecorded is returned by a replay provider for requests missing from the cassette
+var ErrNotRecorded = errors.New("request not recorded in cassette")
+
+// RecordedRequest is the part of a request that identifies an interaction
+type RecordedRequest struct {
+	Input    string `json:"input"`
+	Language string `json:"language,omitempty"`
+	Dialect  string `json:"dialect,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Interaction is a request with the answer a provider gave
+type Interaction struct {
+	Request  RecordedRequest      `json:"request"`
+	Response *ai.ScheduleResponse `json:"response,omitempty"`
+	// Error is the message of a failed request
+	Error string `json:"error,omitempty"`
+	// StatusCode is set for failures reported as *ai.HTTPError, so that
+	// replayed errors are retried like the recorded ones
+	StatusCode int `json:"status_code,omitempty"`
+}
+
+// Cassette is a list of interactions, stored as a JSON file
+type Cassette struct {
+	mu           sync.Mutex
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette file
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to a file, replacing it atomically
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cassette-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func (c *Cassette) add(interaction Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, interaction)
+}
+
+// recordedRequest keeps the identifying part of a request
+func recordedRequest(req ai.ScheduleRequest) RecordedRequest {
+	r := RecordedRequest{
+		Input:    req.Input,
+		Language: req.Language,
+		Dialect:  req.Dialect.Name,
+	}
+	if req.Location != nil {
+		r.Timezone = req.Location.String()
+	}
+	return r
+}
+
+// RecordingProvider passes requests to a real provider and writes every
+// interaction to a cassette file
+type RecordingProvider struct {
+	provider ai.ScheduleProvider
+	cassette *Cassette
+	path     string
+	// mu serializes writes of the file
+	mu sync.Mutex
+}
+
+// NewRecordingProvider records the answers of a provider to the cassette file
+// at path, starting a new cassette
+func NewRecordingProvider(provider ai.ScheduleProvider, path string) *RecordingProvider {
+	return &RecordingProvider{
+		provider: provider,
+		cassette: &Cassette{},
+		path:     path,
+	}
+}
+
+// GenerateSchedule implements the ai.ScheduleProvider interface. The cassette
+// is saved after every request, and a failure to save is returned as an error.
+func (p *RecordingProvider) GenerateSchedule(ctx context.Context, req ai.ScheduleRequest) (*ai.ScheduleResponse, error) {
+	response, err := p.provider.GenerateSchedule(ctx, req)
+
+	interaction := Interaction{Request: recordedRequest(req), Response: response}
+	if err != nil {
+		interaction.Response = nil
+		interaction.Error = err.Error()
+
+		var httpErr *ai.HTTPError
+		if errors.As(err, &httpErr) {
+			interaction.StatusCode = httpErr.StatusCode
+			interaction.Error = httpErr.Message
+		}
+	}
+	p.cassette.add(interaction)
+
+	p.mu.Lock()
+	saveErr := p.cassette.Save(p.path)
+	p.mu.Unlock()
+	if saveErr != nil {
+		return nil, errors.Join(err, saveErr)
+	}
+
+	return response, err
+}
+
+// GenerateCron implements the ai.AIProvider interface
+func (p *RecordingProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	response, err := p.GenerateSchedule(ctx, ai.ScheduleRequest{Input: input})
+	if err != nil {
+		return "", err
+	}
+	return response.Expression, nil
+}
+
+// Cassette returns the interactions recorded so far
+func (p *RecordingProvider) Cassette() *Cassette {
+	return p.cassette
+}
+
+// ReplayProvider answers from a cassette without calling a real provider.
+// Requests are matched on the input, language, dialect and time zone.
+// Interactions recorded for the same request are served in order, the last
+// one once the others are used up.
+type ReplayProvider struct {
+	mu           sync.Mutex
+	interactions map[RecordedRequest][]Interaction
+	served       map[RecordedRequest]int
+}
+
+// NewReplayProvider replays the interactions of a cassette
+func NewReplayProvider(cassette *Cassette) *ReplayProvider {
+	p := &ReplayProvider{
+		interactions: make(map[RecordedRequest][]Interaction),
+		served:       make(map[RecordedRequest]int),
+	}
+
+	cassette.mu.Lock()
+	defer cassette.mu.Unlock()
+	for _, interaction := range cassette.Interactions {
+		p.interactions[interaction.Request] = append(p.interactions[interaction.Request], interaction)
+	}
+	return p
+}
+
+// GenerateSchedule implements the ai.ScheduleProvider interface
+func (p *ReplayProvider) GenerateSchedule(ctx context.Context, req ai.ScheduleRequest) (*ai.ScheduleResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	key := recordedRequest(req)
+
+	p.mu.Lock()
+	interactions := p.interactions[key]
+	n := p.served[key]
+	p.served[key]++
+	p.mu.Unlock()
+
+	if len(interactions) == 0 {
+		return nil, fmt.Errorf("%w: %+v", ErrNotRecorded, key)
+	}
+
+	interaction := interactions[min(n, len(interactions)-1)]
+	switch {
+	case interaction.StatusCode != 0:
+		return nil, &ai.HTTPError{StatusCode: interaction.StatusCode, Message: interaction.Error}
+	case interaction.Error != "":
+		return nil, errors.New(interaction.Error)
+	case interaction.Response == nil:
+		return nil, fmt.Errorf("%w: %+v has no response", ErrNotRecorded, key)
+	}
+
+	response := *interaction.Response
+	return &response, nil
+}
+
+// GenerateCron implements the ai.AIProvider interface
+func (p *ReplayProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	response, err := p.GenerateSchedule(ctx, ai.ScheduleRequest{Input: input})
+	if err != nil {
+		return "", err
+	}
+	return response.Expression, nil
+}
@@ -0,0 +1,90 @@
+package aitest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	live := NewFake().
+		On("business mornings", Reply{Response: ai.ScheduleResponse{Expression: "0 9 * * 1-5", Model: "live"}}).
+		On("at teatime", Fail(&ai.HTTPError{StatusCode: 503, Message: "overloaded"}), Answer("0 16 * * *"))
+
+	recorder := NewRecordingProvider(live, path)
+	location, _ := time.LoadLocation("Europe/Amsterdam")
+	requests := []ai.ScheduleRequest{
+		{Input: "business mornings", Language: "en"},
+		{Input: "at teatime", Language: "en", Location: location},
+		{Input: "at teatime", Language: "en", Location: location},
+	}
+
+	ctx := context.Background()
+	for _, req := range requests {
+		recorder.GenerateSchedule(ctx, req)
+	}
+	if live.Calls("") != 3 {
+		t.Fatalf("live provider called %d times", live.Calls(""))
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cassette.Interactions) != 3 || cassette.Interactions[1].Request.Timezone != "Europe/Amsterdam" {
+		t.Fatalf("cassette = %+v", cassette.Interactions)
+	}
+
+	replay := NewReplayProvider(cassette)
+	response, err := replay.GenerateSchedule(ctx, requests[0])
+	if err != nil || response.Expression != "0 9 * * 1-5" || response.Model != "live" {
+		t.Errorf("replayed %+v, %v", response, err)
+	}
+
+	var httpErr *ai.HTTPError
+	if _, err := replay.GenerateSchedule(ctx, requests[1]); !errors.As(err, &httpErr) || !ai.IsRetryable(err) {
+		t.Errorf("replayed error = %v, want retryable *ai.HTTPError", err)
+	}
+	if response, err := replay.GenerateSchedule(ctx, requests[2]); err != nil || response.Expression != "0 16 * * *" {
+		t.Errorf("replayed %+v, %v", response, err)
+	}
+
+	// The time zone is part of the request
+	if _, err := replay.GenerateSchedule(ctx, ai.ScheduleRequest{Input: "at teatime", Language: "en"}); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("error = %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+func TestReplayWithMapper(t *testing.T) {
+	cassette, err := LoadCassette("testdata/cassette.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapper, err := ai.NewBraveHumanCronMapper("../../core/rules", nil,
+		ai.WithScheduleProvider(NewReplayProvider(cassette)),
+		ai.WithRetry(ai.WithBackoff(time.Millisecond, time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for input, want := range map[string]string{
+		"business mornings": "0 9 * * 1-5",
+		"at teatime":        "0 16 * * *",
+	} {
+		result, err := mapper.ToCronResult(context.Background(), input)
+		if err != nil {
+			t.Errorf("ToCronResult(%q) error = %v", input, err)
+			continue
+		}
+		if result.Expression != want || result.AI.Model != "gpt-4o-mini" {
+			t.Errorf("ToCronResult(%q) = %+v", input, result)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package aitest provides AI providers for deterministic tests of code built
+// on the ai package: a scripted fake, and recording and replaying of a real
+// provider's answers through cassette files.
+package aitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+)
+
+// ErrUnscripted is returned by Fake for inputs without a scripted reply
+var ErrUnscripted = errors.New("no scripted reply")
+
+// Reply is a scripted answer of a Fake
+type Reply struct {
+	// Response is returned when Err is nil
+	Response ai.ScheduleResponse
+	// Err is returned instead of a response when set
+	Err error
+	// Latency delays the reply, unless the context ends first
+	Latency time.Duration
+}
+
+// Answer replies with an expression, or any text containing one
+func Answer(expression string) Reply {
+	return Reply{Response: ai.ScheduleResponse{Expression: expression}}
+}
+
+// Fail replies with an error
+func Fail(err error) Reply {
+	return Reply{Err: err}
+}
+
+// After returns the reply delayed by d
+func (r Reply) After(d time.Duration) Reply {
+	r.Latency = d
+	return r
+}
+
+// Fake is a ScheduleProvider answering from a script of inputs and replies.
+// It is safe for concurrent use.
+type Fake struct {
+	mu       sync.Mutex
+	script   map[string][]Reply
+	fallback *Reply
+	served   map[string]int
+	requests []ai.ScheduleRequest
+}
+
+// NewFake creates a fake without any replies
+func NewFake() *Fake {
+	return &Fake{
+		script: make(map[string][]Reply),
+		served: make(map[string]int),
+	}
+}
+
+// On scripts the replies for an input, matched ignoring case and surrounding
+// whitespace. Repeated requests get the replies in order, and the last reply
+// once the others are used up.
+func (f *Fake) On(input string, replies ...Reply) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := fakeKey(input)
+	f.script[key] = append(f.script[key], replies...)
+	return f
+}
+
+// Otherwise scripts the reply for inputs without replies of their own
+func (f *Fake) Otherwise(reply Reply) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fallback = &reply
+	return f
+}
+
+// GenerateSchedule implements the ai.ScheduleProvider interface
+func (f *Fake) GenerateSchedule(ctx context.Context, req ai.ScheduleRequest) (*ai.ScheduleResponse, error) {
+	reply, err := f.next(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.Latency > 0 {
+		timer := time.NewTimer(reply.Latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+
+	if reply.Err != nil {
+		return nil, reply.Err
+	}
+	response := reply.Response
+	return &response, nil
+}
+
+// GenerateCron implements the ai.AIProvider interface
+func (f *Fake) GenerateCron(ctx context.Context, input string) (string, error) {
+	response, err := f.GenerateSchedule(ctx, ai.ScheduleRequest{Input: input})
+	if err != nil {
+		return "", err
+	}
+	return response.Expression, nil
+}
+
+// next records a request and picks its reply
+func (f *Fake) next(req ai.ScheduleRequest) (Reply, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, req)
+
+	key := fakeKey(req.Input)
+	replies := f.script[key]
+	if len(replies) == 0 {
+		if f.fallback == nil {
+			return Reply{}, fmt.Errorf("%w for %q", ErrUnscripted, req.Input)
+		}
+		return *f.fallback, nil
+	}
+
+	n := f.served[key]
+	f.served[key]++
+	return replies[min(n, len(replies)-1)], nil
+}
+
+// Requests returns the requests received so far
+func (f *Fake) Requests() []ai.ScheduleRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]ai.ScheduleRequest(nil), f.requests...)
+}
+
+// Calls returns the number of requests received for an input, or for all
+// inputs when input is empty
+func (f *Fake) Calls(input string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if input == "" {
+		return len(f.requests)
+	}
+
+	calls := 0
+	for _, req := range f.requests {
+		if fakeKey(req.Input) == fakeKey(input) {
+			calls++
+		}
+	}
+	return calls
+}
+
+func fakeKey(input string) string {
+	return strings.ToLower(strings.TrimSpace(input))
+}
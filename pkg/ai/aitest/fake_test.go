@@ -0,0 +1,55 @@
+package aitest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+)
+
+func TestFakeScript(t *testing.T) {
+	errDown := errors.New("down")
+	fake := NewFake().
+		On("Every Tuesday", Fail(errDown), Answer("0 0 * * 2")).
+		On("hourly", Answer("0 * * * *"))
+
+	ctx := context.Background()
+	if _, err := fake.GenerateCron(ctx, "every tuesday"); !errors.Is(err, errDown) {
+		t.Errorf("first reply error = %v, want %v", err, errDown)
+	}
+	for range 2 {
+		if got, err := fake.GenerateCron(ctx, " every tuesday "); err != nil || got != "0 0 * * 2" {
+			t.Errorf("GenerateCron() = %q, %v", got, err)
+		}
+	}
+
+	if _, err := fake.GenerateCron(ctx, "never"); !errors.Is(err, ErrUnscripted) {
+		t.Errorf("unscripted input error = %v", err)
+	}
+	fake.Otherwise(Answer("I cannot help"))
+	if got, _ := fake.GenerateCron(ctx, "never"); got != "I cannot help" {
+		t.Errorf("fallback reply = %q", got)
+	}
+
+	if fake.Calls("Every Tuesday") != 3 || fake.Calls("") != 5 || len(fake.Requests()) != 5 {
+		t.Errorf("calls = %d of %d", fake.Calls("every tuesday"), fake.Calls(""))
+	}
+}
+
+func TestFakeLatency(t *testing.T) {
+	fake := NewFake().On("slow", Answer("0 0 * * *").After(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := fake.GenerateSchedule(ctx, ai.ScheduleRequest{Input: "slow"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("latency not cut short by the context")
+	}
+}
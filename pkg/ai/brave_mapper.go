@@ -2,10 +2,14 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cache"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
 )
 
 // BraveOption represents a functional option for configuring BraveHumanCronMapper
@@ -21,15 +25,105 @@ func WithAIFirst(useAIFirst bool) BraveOption {
 // WithAIProvider sets a custom AI provider implementation
 func WithAIProvider(provider AIProvider) BraveOption {
 	return func(m *BraveHumanCronMapper) {
-		m.aiProvider = provider
+		m.provider = AdaptProvider(provider)
+	}
+}
+
+// WithScheduleProvider sets a provider implementing the richer ScheduleProvider interface
+func WithScheduleProvider(provider ScheduleProvider) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.provider = provider
+	}
+}
+
+// WithFallbackProviders adds providers tried in order when the main provider fails
+func WithFallbackProviders(providers ...ScheduleProvider) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.fallbacks = append(m.fallbacks, providers...)
+	}
+}
+
+// WithRetry retries failed requests to every provider with exponential backoff
+func WithRetry(options ...RetryOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.retry = true
+		m.retryOptions = options
+	}
+}
+
+// WithAttemptTimeout limits how long a single request to a provider may take
+func WithAttemptTimeout(timeout time.Duration) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.attemptTimeout = timeout
+	}
+}
+
+// WithCircuitBreaker puts a circuit breaker in front of every provider
+func WithCircuitBreaker(options ...BreakerOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.breaker = true
+		m.breakerOptions = options
+	}
+}
+
+// WithCache caches conversions, including AI answers, in the given store.
+// Keys follow core.CronScribe.CacheKey, so new or reloaded rules invalidate
+// them. AI answers without a usable expression are cached for the negative TTL,
+// provider errors are not.
+func WithCache(store cache.Store, options ...cache.Option) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.cache = cache.New(store, options...)
 	}
 }
 
 // BraveHumanCronMapper extends core.CronScribe with AI API capabilities
 type BraveHumanCronMapper struct {
 	coreMapper *core.CronScribe
-	aiProvider AIProvider
+	provider   ScheduleProvider
 	useAIFirst bool
+	cache      *cache.Cache
+	verifier   *verifier
+	recorder   Recorder
+	guard      *InputGuard
+	budget     *Budget
+	router     *router
+	shadow     *shadow
+	hedge      *hedge
+	nearest    *NearestProvider
+
+	fallbacks      []ScheduleProvider
+	retry          bool
+	retryOptions   []RetryOption
+	attemptTimeout time.Duration
+	breaker        bool
+	breakerOptions []BreakerOption
+
+	chain  *ChainProvider
+	stacks []providerStack
+}
+
+// providerStack holds the wrappers built around one provider
+type providerStack struct {
+	timeout *TimeoutProvider
+	retry   *RetryProvider
+	breaker *CircuitBreaker
+	limiter *RateLimitedProvider
+}
+
+// ProviderStats holds the counters of the wrappers around one provider, nil
+// for wrappers that are not configured
+type ProviderStats struct {
+	Timeout   *TimeoutStats   `json:"timeout,omitempty"`
+	Retry     *RetryStats     `json:"retry,omitempty"`
+	Breaker   *BreakerStats   `json:"breaker,omitempty"`
+	RateLimit *RateLimitStats `json:"rate_limit,omitempty"`
+}
+
+// ResilienceStats holds the counters of the provider chain and of the wrappers
+// around every provider, in chain order
+type ResilienceStats struct {
+	Chain     *ChainStats     `json:"chain,omitempty"`
+	Providers []ProviderStats `json:"providers"`
 }
 
 // NewBraveHumanCronMapper creates a new brave mapper that can use both local rules and AI
@@ -40,15 +134,19 @@ func NewBraveHumanCronMapper(rulesDir string, provider AIProvider, options ...Br
 		return nil, fmt.Errorf("failed to create base mapper: %w", err)
 	}
 
-	// Create the mapper with provided AI provider
-	if provider == nil {
-		return nil, fmt.Errorf("AI provider cannot be nil")
-	}
+	return newBraveMapper(coreMapper, provider, options...)
+}
 
+// newBraveMapper wires a core instance, a provider and the options together.
+// The provider may be nil when an option sets one.
+func newBraveMapper(coreMapper *core.CronScribe, provider AIProvider, options ...BraveOption) (*BraveHumanCronMapper, error) {
 	mapper := &BraveHumanCronMapper{
 		coreMapper: coreMapper,
-		aiProvider: provider,
 		useAIFirst: false, // Default to using local rules first
+		guard:      NewInputGuard(),
+	}
+	if provider != nil {
+		mapper.provider = AdaptProvider(provider)
 	}
 
 	// Apply all options
@@ -56,39 +154,184 @@ func NewBraveHumanCronMapper(rulesDir string, provider AIProvider, options ...Br
 		option(mapper)
 	}
 
+	if mapper.provider == nil {
+		if mapper.nearest == nil {
+			return nil, fmt.Errorf("AI provider cannot be nil")
+		}
+		// The known phrasings are the only provider
+		if len(mapper.fallbacks) == 0 {
+			return mapper, nil
+		}
+		mapper.provider, mapper.fallbacks = mapper.fallbacks[0], mapper.fallbacks[1:]
+	}
+
+	mapper.wrapProviders()
 	return mapper, nil
 }
 
+// wrapProviders builds the configured timeout, retry, circuit breaker, rate
+// limit and chain wrappers. Every attempt gets its own timeout, the breaker
+// counts a request as failed only once all its retries failed, and retries do
+// not count against the rate limit.
+func (m *BraveHumanCronMapper) wrapProviders() {
+	providers := append([]ScheduleProvider{m.provider}, m.fallbacks...)
+
+	for i, provider := range providers {
+		var stack providerStack
+		if m.attemptTimeout > 0 {
+			stack.timeout = NewTimeoutProvider(provider, m.attemptTimeout)
+			provider = stack.timeout
+		}
+		if m.retry {
+			stack.retry = NewRetryProvider(provider, m.retryOptions...)
+			provider = stack.retry
+		}
+		if m.breaker {
+			stack.breaker = NewCircuitBreaker(provider, m.breakerOptions...)
+			provider = stack.breaker
+		}
+		if m.budget != nil && m.budget.providerRate > 0 {
+			stack.limiter = NewRateLimitedProvider(provider, m.budget.providerRate, m.budget.providerBurst)
+			provider = stack.limiter
+		}
+		providers[i] = provider
+		m.stacks = append(m.stacks, stack)
+	}
+
+	m.provider = providers[0]
+	if len(providers) > 1 {
+		m.chain = NewChainProvider(providers...)
+		m.provider = m.chain
+	}
+	if m.budget != nil {
+		m.provider = &meteredProvider{provider: m.provider, budget: m.budget}
+	}
+}
+
+// Stats returns the counters of the configured provider wrappers, for logging
+func (m *BraveHumanCronMapper) Stats() ResilienceStats {
+	var stats ResilienceStats
+	if m.chain != nil {
+		chain := m.chain.Stats()
+		stats.Chain = &chain
+	}
+
+	for _, stack := range m.stacks {
+		var p ProviderStats
+		if stack.timeout != nil {
+			timeout := stack.timeout.Stats()
+			p.Timeout = &timeout
+		}
+		if stack.retry != nil {
+			retry := stack.retry.Stats()
+			p.Retry = &retry
+		}
+		if stack.breaker != nil {
+			breaker := stack.breaker.Stats()
+			p.Breaker = &breaker
+		}
+		if stack.limiter != nil {
+			limiter := stack.limiter.Stats()
+			p.RateLimit = &limiter
+		}
+		stats.Providers = append(stats.Providers, p)
+	}
+	return stats
+}
+
 // ToCron converts a human-readable expression to a cron expression
 // In brave mode, it can use AI if local rules fail or if useAIFirst is true
 func (m *BraveHumanCronMapper) ToCron(expression string) (string, error) {
+	result, err := m.ToCronResult(context.Background(), expression)
+	if err != nil {
+		return "", err
+	}
+	return result.Expression, nil
+}
+
+// ToCronResult converts a human-readable expression like ToCron, and reports
+// which engine produced the expression along with its details
+func (m *BraveHumanCronMapper) ToCronResult(ctx context.Context, expression string) (*Result, error) {
+	return m.cached(expression, false, func() (*Result, error) {
+		return m.toCronResult(ctx, expression)
+	})
+}
+
+func (m *BraveHumanCronMapper) toCronResult(ctx context.Context, expression string) (*Result, error) {
+	if core.DetectSyntax(expression) != core.SyntaxNatural {
+		return m.machineSyntax(expression, false)
+	}
+	if m.shadow != nil {
+		return m.shadowed(ctx, expression, false)
+	}
+	if m.hedge != nil {
+		return m.hedged(ctx, expression, false)
+	}
+	if m.router != nil {
+		return m.routed(ctx, expression, false)
+	}
+
+	var aiErr error
 	if m.useAIFirst {
 		// Try AI first
-		ctx := context.Background()
-		cronExpr, err := m.aiProvider.GenerateCron(ctx, expression)
-		if err == nil && isValidCronExpression(cronExpr) {
-			return cronExpr, nil
+		result, err := m.generate(ctx, m.request(expression, false, nil))
+		if err == nil {
+			return result, nil
 		}
 		// If AI fails, fall back to local rules
+		aiErr = err
 	}
 
 	// Try local rules
-	cronExpr, err := m.coreMapper.Convert(expression)
-	if err == nil {
-		return cronExpr, nil
+	rulesResult, rulesErr := m.coreMapper.ConvertResult(expression)
+	if rulesErr == nil {
+		return rulesOutcome(rulesResult), nil
 	}
 
 	// If local rules fail and we didn't try AI yet, use AI as fallback
 	if !m.useAIFirst {
-		ctx := context.Background()
-		cronExpr, err := m.aiProvider.GenerateCron(ctx, expression)
-		if err == nil && isValidCronExpression(cronExpr) {
-			return cronExpr, nil
+		result, err := m.generate(ctx, m.request(expression, false, rulesErr))
+		if err == nil {
+			return result, nil
+		}
+		if m.rulesOnly(err) {
+			return nil, rulesErr
 		}
-		return "", fmt.Errorf("unable to convert expression with local rules or AI: %s", expression)
+		return nil, fmt.Errorf("unable to convert expression with local rules or AI: %s: %w", expression, err)
 	}
 
-	return "", err
+	// A rejected input or an exhausted budget is more useful to the user than
+	// the rules failure
+	var inputErr *InputError
+	if errors.As(aiErr, &inputErr) || (errors.Is(aiErr, ErrBudgetExceeded) && !m.rulesOnly(aiErr)) {
+		return nil, aiErr
+	}
+	return nil, rulesErr
+}
+
+// machineSyntax converts inputs already written as cron expressions or macros
+// with the core alone: they need no AI, whatever the routing mode
+func (m *BraveHumanCronMapper) machineSyntax(expression string, autoDetect bool) (*Result, error) {
+	convert := m.coreMapper.ConvertResult
+	if autoDetect {
+		convert = m.coreMapper.AutoDetectResult
+	}
+	result, err := convert(expression)
+	if err != nil {
+		return nil, err
+	}
+	return rulesOutcome(result), nil
+}
+
+// rulesOnly reports whether an AI failure is a budget refusal the mapper
+// answers with the rules outcome
+func (m *BraveHumanCronMapper) rulesOnly(err error) bool {
+	return m.budget != nil && m.budget.policy == RulesOnly && errors.Is(err, ErrBudgetExceeded)
+}
+
+// Budget returns the budget set with WithBudget, nil when there is none
+func (m *BraveHumanCronMapper) Budget() *Budget {
+	return m.budget
 }
 
 // SetLanguage sets the language for the underlying mapper
@@ -103,29 +346,246 @@ func (m *BraveHumanCronMapper) GetSupportedLanguages() []string {
 
 // AutoDetect tries to automatically detect the language and convert the expression
 func (m *BraveHumanCronMapper) AutoDetect(expression string) (string, error) {
+	result, err := m.AutoDetectResult(context.Background(), expression)
+	if err != nil {
+		return "", err
+	}
+	return result.Expression, nil
+}
+
+// AutoDetectResult is AutoDetect that reports which engine produced the expression
+func (m *BraveHumanCronMapper) AutoDetectResult(ctx context.Context, expression string) (*Result, error) {
+	return m.cached(expression, true, func() (*Result, error) {
+		return m.autoDetectResult(ctx, expression)
+	})
+}
+
+func (m *BraveHumanCronMapper) autoDetectResult(ctx context.Context, expression string) (*Result, error) {
+	if core.DetectSyntax(expression) != core.SyntaxNatural {
+		return m.machineSyntax(expression, true)
+	}
+	if m.shadow != nil {
+		return m.shadowed(ctx, expression, true)
+	}
+	if m.hedge != nil {
+		return m.hedged(ctx, expression, true)
+	}
+	if m.router != nil {
+		return m.routed(ctx, expression, true)
+	}
+
 	// Try with local rules first
-	cronExpr, err := m.coreMapper.AutoDetect(expression)
-	if err == nil {
-		return cronExpr, nil
+	rulesResult, rulesErr := m.coreMapper.AutoDetectResult(expression)
+	if rulesErr == nil {
+		return rulesOutcome(rulesResult), nil
 	}
 
 	// Fall back to AI
-	ctx := context.Background()
-	cronExpr, err = m.aiProvider.GenerateCron(ctx, expression)
-	if err == nil && isValidCronExpression(cronExpr) {
-		return cronExpr, nil
+	result, err := m.generate(ctx, m.request(expression, true, rulesErr))
+	if err == nil {
+		return result, nil
+	}
+	if m.rulesOnly(err) {
+		return nil, rulesErr
 	}
 
-	return "", fmt.Errorf("unable to convert expression with local rules or AI: %s", expression)
+	return nil, fmt.Errorf("unable to convert expression with local rules or AI: %s: %w", expression, err)
+}
+
+// cachedFailure is the cached form of an AI answer without a usable expression
+type cachedFailure struct {
+	Message    string   `json:"message"`
+	Response   string   `json:"response"`
+	Candidates []string `json:"candidates,omitempty"`
 }
 
-// isValidCronExpression performs basic validation on a cron expression
-func isValidCronExpression(expr string) bool {
-	// Trim the expression and remove any quotation marks that might be in the AI response
-	expr = strings.TrimSpace(expr)
-	expr = strings.Trim(expr, `"'`)
+// cached serves a conversion from the cache, or runs it and caches the outcome
+func (m *BraveHumanCronMapper) cached(expression string, autoDetect bool, convert func() (*Result, error)) (*Result, error) {
+	if m.cache == nil {
+		return convert()
+	}
+
+	mode := fmt.Sprint(m.useAIFirst)
+	switch {
+	case m.shadow != nil:
+		mode = "shadow"
+	case m.hedge != nil:
+		mode = m.hedge.String()
+	case m.router != nil:
+		mode = m.router.String()
+	}
+	key := cache.Key("ai", mode, m.coreMapper.CacheKey(expression, autoDetect))
+
+	if entry, ok := m.cache.Get(key); ok {
+		if entry.Failure {
+			var failed cachedFailure
+			if entry.Decode(&failed) == nil {
+				return nil, &cache.Error{
+					Message: failed.Message,
+					Err:     &ExtractionError{Response: failed.Response, Candidates: failed.Candidates},
+				}
+			}
+		} else {
+			var result Result
+			if entry.Decode(&result) == nil {
+				return &result, nil
+			}
+		}
+	}
+
+	result, err := convert()
+	var extractionErr *ExtractionError
+	switch {
+	case err == nil:
+		m.cache.Put(key, result)
+	case errors.As(err, &extractionErr):
+		m.cache.PutFailure(key, cachedFailure{
+			Message:    err.Error(),
+			Response:   extractionErr.Response,
+			Candidates: extractionErr.Candidates,
+		})
+	}
+	return result, err
+}
+
+// request builds the provider request from the state of the core mapper
+func (m *BraveHumanCronMapper) request(expression string, autoDetect bool, rulesErr error) ScheduleRequest {
+	req := ScheduleRequest{
+		Input:      expression,
+		Language:   m.coreMapper.Language(),
+		Dialect:    m.coreMapper.Dialect(),
+		Location:   m.coreMapper.Location(),
+		RulesError: rulesErr,
+	}
+	if autoDetect {
+		req.Language = ""
+	}
+	req.Examples = m.examples(req.Language)
+	return req
+}
+
+// examples collects one example per rule of a language, or of every language
+// when the language is unknown
+func (m *BraveHumanCronMapper) examples(language string) []Example {
+	languages := []string{language}
+	if language == "" {
+		languages = m.coreMapper.GetSupportedLanguages()
+		sort.Strings(languages)
+	}
+
+	var examples []Example
+	for _, lang := range languages {
+		set := m.coreMapper.RuleSet(lang)
+		if set == nil {
+			continue
+		}
+		for _, rule := range set.Rules {
+			for _, example := range rule.Examples {
+				if !example.Error {
+					examples = append(examples, Example{Input: example.Input, Cron: example.Cron})
+					break
+				}
+			}
+		}
+	}
+	return examples
+}
+
+// generate asks the AI provider for a valid cron expression and records fallbacks
+func (m *BraveHumanCronMapper) generate(ctx context.Context, req ScheduleRequest) (*Result, error) {
+	input, err := m.guard.Check(req.Input)
+	if err != nil {
+		return nil, err
+	}
+	req.Input = input
+
+	// Known phrasings are free, so they are tried before any budget or paid provider
+	var nearestErr error
+	if m.nearest != nil {
+		result, err := m.nearestResult(ctx, req)
+		if err == nil {
+			return result, nil
+		}
+		nearestErr = err
+	}
+	if m.provider == nil {
+		return nil, nearestErr
+	}
+
+	if m.budget != nil {
+		if err := m.budget.Allow(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := m.ask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inputs the rules failed on are candidates for new rules, unless the answer is in doubt
+	confident := result.Verification == nil || result.Verification.Confident
+	if m.recorder != nil && req.RulesError != nil && confident {
+		m.recorder.Record(ctx, Record{
+			Time:       time.Now(),
+			Input:      req.Input,
+			Language:   req.Language,
+			Expression: result.Expression,
+			Model:      result.AI.Model,
+		})
+	}
+	return result, nil
+}
+
+// ask gets an answer from the provider, verified when configured
+func (m *BraveHumanCronMapper) ask(ctx context.Context, req ScheduleRequest) (*Result, error) {
+	if m.verifier != nil {
+		return m.generateVerified(ctx, req)
+	}
+
+	response, err := m.provider.GenerateSchedule(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	expression, err := m.output(response, req)
+	if err != nil {
+		return nil, err
+	}
+	response.Expression = expression
+
+	return &Result{Expression: expression, Source: SourceAI, AI: response}, nil
+}
+
+// output returns the expression of an answer. Structured answers were
+// validated and rendered in the request's dialect by the provider, other
+// answers go through the output guard.
+func (m *BraveHumanCronMapper) output(response *ScheduleResponse, req ScheduleRequest) (string, error) {
+	if response.Structured != nil {
+		return response.Expression, nil
+	}
+	return m.guard.Output(response.Expression, req.Dialect)
+}
+
+// nearestResult converts an input like a known phrasing. Its answers are not
+// recorded, as they come from the rules and recorded answers already.
+func (m *BraveHumanCronMapper) nearestResult(ctx context.Context, req ScheduleRequest) (*Result, error) {
+	response, err := m.nearest.GenerateSchedule(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := cron.Parse(response.Expression)
+	if err != nil {
+		return nil, err
+	}
+	if response.Expression, err = orStandard(req.Dialect).Render(expr); err != nil {
+		return nil, err
+	}
+	return &Result{Expression: response.Expression, Source: SourceNearest, AI: response}, nil
+}
 
-	// Basic validation: check if it has 5 fields separated by whitespace
-	fields := strings.Fields(expr)
-	return len(fields) == 5
+// rulesOutcome wraps a rules engine result
+func rulesOutcome(result *core.Result) *Result {
+	return &Result{Expression: result.Expression, Source: SourceRules, Rules: result}
 }
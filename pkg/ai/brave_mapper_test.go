@@ -0,0 +1,200 @@
+package ai_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+	"github.com/flaticols/cronscribe/pkg/ai/aitest"
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+var errOffline = errors.New("provider offline")
+
+func TestConversionOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		aiFirst    bool
+		autoDetect bool
+		input      string
+		reply      aitest.Reply
+		want       string
+		source     ai.Source
+		calls      int
+		err        error
+	}{
+		{"rules match", false, false, "every day at 9:30", aitest.Answer("0 0 * * *"), "30 9 * * *", ai.SourceRules, 0, nil},
+		{"ai fallback", false, false, "business mornings", aitest.Answer("0 9 * * 1-5"), "0 9 * * 1-5", ai.SourceAI, 1, nil},
+		{"ai fallback fails", false, false, "business mornings", aitest.Fail(errOffline), "", "", 1, errOffline},
+		{"ai first", true, false, "every day at 9:30", aitest.Answer("0 0 * * *"), "0 0 * * *", ai.SourceAI, 1, nil},
+		{"ai first fails, rules match", true, false, "every day at 9:30", aitest.Fail(errOffline), "30 9 * * *", ai.SourceRules, 1, nil},
+		{"ai first invalid, rules match", true, false, "every day at 9:30", aitest.Answer("no idea"), "30 9 * * *", ai.SourceRules, 1, nil},
+		{"ai first fails, rules fail", true, false, "business mornings", aitest.Fail(errOffline), "", "", 1, rules.ErrNoMatch},
+		{"auto detect rules match", false, true, "elke dag om 9 vm", aitest.Answer("0 0 * * *"), "0 9 * * *", ai.SourceRules, 0, nil},
+		{"auto detect ignores ai first", true, true, "каждый день в 10:00", aitest.Answer("0 0 * * *"), "0 10 * * *", ai.SourceRules, 0, nil},
+		{"auto detect ai fallback", false, true, "business mornings", aitest.Answer("0 9 * * 1-5"), "0 9 * * 1-5", ai.SourceAI, 1, nil},
+		{"auto detect ai fails", true, true, "business mornings", aitest.Fail(errOffline), "", "", 1, errOffline},
+		{"cron ai first", true, false, "0 9 * * 1", aitest.Answer("1 1 1 1 1"), "0 9 * * 1", ai.SourceRules, 0, nil},
+		{"cron normalized", false, false, "0 0 9 ? * MON", aitest.Answer("1 1 1 1 1"), "0 9 * * 1", ai.SourceRules, 0, nil},
+		{"macro ai first", true, false, "@daily", aitest.Answer("1 1 1 1 1"), "0 0 * * *", ai.SourceRules, 0, nil},
+		{"interval ai first", true, false, "@every 1h30m", aitest.Answer("1 1 1 1 1"), "@every 1h30m", ai.SourceRules, 0, nil},
+		{"auto detect macro", false, true, "@hourly", aitest.Answer("1 1 1 1 1"), "0 * * * *", ai.SourceRules, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := aitest.NewFake().Otherwise(tt.reply)
+			mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil,
+				ai.WithScheduleProvider(fake),
+				ai.WithAIFirst(tt.aiFirst),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			convert := mapper.ToCronResult
+			if tt.autoDetect {
+				convert = mapper.AutoDetectResult
+			}
+			result, err := convert(context.Background(), tt.input)
+
+			if fake.Calls("") != tt.calls {
+				t.Errorf("provider called %d times, want %d", fake.Calls(""), tt.calls)
+			}
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Expression != tt.want || result.Source != tt.source {
+				t.Errorf("result = %s from %s, want %s from %s", result.Expression, result.Source, tt.want, tt.source)
+			}
+
+			// The plain methods agree with the detailed ones
+			plain := mapper.ToCron
+			if tt.autoDetect {
+				plain = mapper.AutoDetect
+			}
+			if expression, err := plain(tt.input); err != nil || expression != tt.want {
+				t.Errorf("plain conversion = %q, %v, want %q", expression, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoDetectRequest(t *testing.T) {
+	fake := aitest.NewFake().On("business mornings", aitest.Answer("0 9 * * 1-5"))
+	mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil, ai.WithScheduleProvider(fake))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mapper.AutoDetect("business mornings"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapper.ToCron("business mornings"); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 2 || requests[0].Language != "" || requests[1].Language != "en" {
+		t.Errorf("requests = %+v", requests)
+	}
+	if !errors.Is(requests[0].RulesError, rules.ErrNoMatch) {
+		t.Errorf("auto detect request lacks the rules error: %v", requests[0].RulesError)
+	}
+}
+
+func TestAIAnswerDialect(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dialect cron.Dialect
+		want    string
+	}{
+		{"seconds", cron.StandardSeconds, "CRON_TZ=Europe/Amsterdam 0 0 9 * * 1"},
+		{"tz prefix", cron.Standard.WithTimezonePrefix("TZ"), "TZ=Europe/Amsterdam 0 9 * * 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := core.New("../core/rules", core.WithDialect(tt.dialect), core.WithLocation(amsterdam))
+			if err != nil {
+				t.Fatal(err)
+			}
+			fake := aitest.NewFake().Otherwise(aitest.Answer("CRON_TZ=Europe/Amsterdam 0 9 * * MON"))
+
+			for _, strict := range []bool{false, true} {
+				mapper, err := ai.WithCore(cs, nil, ai.WithScheduleProvider(fake), ai.WithInputGuard(ai.WithStrictOutput(strict)))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				result, err := mapper.ToCronResult(context.Background(), "business mornings")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Source != ai.SourceAI || result.Expression != tt.want {
+					t.Errorf("strict %v: result = %s from %s, want %s", strict, result.Expression, result.Source, tt.want)
+				}
+			}
+
+			// Rules answers come out in the same dialect
+			rules, err := cs.Convert("every monday at 9am")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rules != tt.want {
+				t.Errorf("rules = %s, want %s", rules, tt.want)
+			}
+		})
+	}
+}
+
+func TestMachineSyntaxSkipsAI(t *testing.T) {
+	modes := map[string]ai.BraveOption{
+		"hedge prefer ai": ai.WithHedging(ai.HedgePreferAI),
+		"routing":         ai.WithRouting(),
+		"shadow":          ai.WithShadow(ai.ShadowReporterFunc(func(ai.ShadowReport) {})),
+	}
+
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			fake := aitest.NewFake().Otherwise(aitest.Answer("1 1 1 1 1"))
+			mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil, ai.WithScheduleProvider(fake), ai.WithAIFirst(true), mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for input, want := range map[string]string{"0 9 * * 1": "0 9 * * 1", "@daily": "0 0 * * *"} {
+				result, err := mapper.ToCronResult(context.Background(), input)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Expression != want || result.Source != ai.SourceRules {
+					t.Errorf("%s = %s from %s, want %s from rules", input, result.Expression, result.Source, want)
+				}
+			}
+
+			// Invalid cron fails with the parser's error instead of asking the AI
+			if _, err := mapper.ToCron("0 25 * * *"); err == nil {
+				t.Error("invalid cron expression was accepted")
+			}
+			if calls := len(fake.Requests()); calls != 0 {
+				t.Errorf("provider called %d times, want 0", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned while a circuit breaker short-circuits its provider
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen rejects every request until the cool-down period is over
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a single trial request through after the cool-down
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerStats holds the state and counters of a CircuitBreaker
+type BreakerStats struct {
+	// State is the current state of the breaker
+	State BreakerState `json:"state"`
+	// ConsecutiveFailures is the number of failures since the last success
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// Calls is the number of requests received
+	Calls int64 `json:"calls"`
+	// Rejected is the number of requests short-circuited while open
+	Rejected int64 `json:"rejected"`
+	// Failures is the number of requests the wrapped provider failed
+	Failures int64 `json:"failures"`
+	// Trips is the number of times the breaker opened
+	Trips int64 `json:"trips"`
+}
+
+// BreakerOption represents a functional option for configuring CircuitBreaker
+type BreakerOption func(*CircuitBreaker)
+
+// WithFailureThreshold sets how many consecutive failures open the breaker, 5 by default
+func WithFailureThreshold(failures int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if failures > 0 {
+			b.threshold = failures
+		}
+	}
+}
+
+// WithCooldown sets how long the breaker stays open, 30s by default
+func WithCooldown(cooldown time.Duration) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.cooldown = cooldown
+	}
+}
+
+// CircuitBreaker stops calling a failing provider for a cool-down period, then
+// lets one trial request through to decide whether to close again
+type CircuitBreaker struct {
+	provider  ScheduleProvider
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	stats    BreakerStats
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker wraps a provider with a circuit breaker
+func NewCircuitBreaker(provider ScheduleProvider, options ...BreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		provider:  provider,
+		threshold: 5,
+		cooldown:  30 * time.Second,
+		now:       time.Now,
+		stats:     BreakerStats{State: BreakerClosed},
+	}
+
+	// Apply all options
+	for _, option := range options {
+		option(b)
+	}
+
+	return b
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (b *CircuitBreaker) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.provider.GenerateSchedule(ctx, req)
+	b.record(ctx, err)
+	return resp, err
+}
+
+// GenerateCron implements the AIProvider interface
+func (b *CircuitBreaker) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, b, input)
+}
+
+// Stats returns a snapshot of the breaker state and counters
+func (b *CircuitBreaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats
+	if stats.State == BreakerOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		stats.State = BreakerHalfOpen
+	}
+	return stats
+}
+
+// allow decides whether a request may reach the provider
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.Calls++
+	if b.stats.State == BreakerOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		b.stats.State = BreakerHalfOpen
+	}
+
+	switch {
+	case b.stats.State == BreakerOpen, b.stats.State == BreakerHalfOpen && b.trial:
+		b.stats.Rejected++
+		return ErrCircuitOpen
+	case b.stats.State == BreakerHalfOpen:
+		b.trial = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a request
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	halfOpen := b.stats.State == BreakerHalfOpen
+	b.trial = false
+
+	switch {
+	case err == nil:
+		b.stats.ConsecutiveFailures = 0
+		b.stats.State = BreakerClosed
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		// The caller gave up, this says nothing about the provider. A
+		// cancelled trial leaves the breaker half-open for the next request.
+	default:
+		b.stats.Failures++
+		b.stats.ConsecutiveFailures++
+		if halfOpen || b.stats.ConsecutiveFailures >= b.threshold {
+			b.stats.State = BreakerOpen
+			b.stats.Trips++
+			b.openedAt = b.now()
+		}
+	}
+}
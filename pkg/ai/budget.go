@@ -0,0 +1,329 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is matched by every *BudgetError with errors.Is
+var ErrBudgetExceeded = errors.New("AI budget exceeded")
+
+// BudgetReason tells which limit refused a request
+type BudgetReason string
+
+const (
+	// BudgetProviderRate is reported when a provider's rate limit is reached
+	BudgetProviderRate BudgetReason = "provider_rate"
+	// BudgetCallerRate is reported when a caller's rate limit is reached
+	BudgetCallerRate BudgetReason = "caller_rate"
+	// BudgetDailyTokens is reported when the daily token ceiling is spent
+	BudgetDailyTokens BudgetReason = "daily_tokens"
+	// BudgetDailyCost is reported when the daily cost ceiling is spent
+	BudgetDailyCost BudgetReason = "daily_cost"
+)
+
+// BudgetError is returned when a budget limit refuses an AI request
+type BudgetError struct {
+	Reason BudgetReason
+	// Caller is the caller key of the request, for caller rate limits
+	Caller string
+	// RetryAfter is when a rate limited request may succeed, 0 when unknown
+	RetryAfter time.Duration
+}
+
+func (e *BudgetError) Error() string {
+	msg := fmt.Sprintf("%s (%s)", ErrBudgetExceeded, e.Reason)
+	if e.Caller != "" {
+		msg += fmt.Sprintf(" for caller %q", e.Caller)
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter.Round(time.Millisecond))
+	}
+	return msg
+}
+
+// Is makes errors.Is(err, ErrBudgetExceeded) report true
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// ExhaustionPolicy decides what a mapper does when the budget refuses an AI request
+type ExhaustionPolicy int
+
+const (
+	// FailFast returns the *BudgetError
+	FailFast ExhaustionPolicy = iota
+	// RulesOnly answers with the rules engine outcome, as if no AI was configured
+	RulesOnly
+)
+
+type callerKey struct{}
+
+// ContextWithCaller attaches a caller key, such as a user id or client IP, to
+// a context. Caller rate limits apply per key.
+func ContextWithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller key of a context, empty when there is none
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// Price is the cost of a model per million tokens
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// BudgetOption represents a functional option for configuring Budget
+type BudgetOption func(*Budget)
+
+// WithRateLimit allows rate requests per second to every provider of a mapper,
+// with bursts of up to burst requests
+func WithRateLimit(rate float64, burst int) BudgetOption {
+	return func(b *Budget) {
+		b.providerRate, b.providerBurst = rate, burst
+	}
+}
+
+// WithCallerRateLimit allows rate conversions per second through the AI for
+// every caller key, with bursts of up to burst conversions. Requests without
+// a key share one limit.
+func WithCallerRateLimit(rate float64, burst int) BudgetOption {
+	return func(b *Budget) {
+		b.callerRate, b.callerBurst = rate, burst
+	}
+}
+
+// WithDailyTokens limits the tokens spent per UTC day
+func WithDailyTokens(limit int64) BudgetOption {
+	return func(b *Budget) {
+		b.state.TokenLimit = limit
+	}
+}
+
+// WithDailyCost limits the cost per UTC day, computed from the prices set
+// with WithPrice
+func WithDailyCost(limit float64) BudgetOption {
+	return func(b *Budget) {
+		b.state.CostLimit = limit
+	}
+}
+
+// WithPrice sets the price of a model. The price of the empty model applies
+// to models without a price of their own.
+func WithPrice(model string, promptPerMillion, completionPerMillion float64) BudgetOption {
+	return func(b *Budget) {
+		b.prices[model] = Price{Prompt: promptPerMillion, Completion: completionPerMillion}
+	}
+}
+
+// WithExhaustion sets what happens when the budget refuses a request, FailFast by default
+func WithExhaustion(policy ExhaustionPolicy) BudgetOption {
+	return func(b *Budget) {
+		b.policy = policy
+	}
+}
+
+// BudgetState is a snapshot of a budget, for dashboards
+type BudgetState struct {
+	// Day is the UTC day the spending is counted for
+	Day        string  `json:"day"`
+	Tokens     int64   `json:"tokens"`
+	TokenLimit int64   `json:"token_limit,omitempty"`
+	Cost       float64 `json:"cost"`
+	CostLimit  float64 `json:"cost_limit,omitempty"`
+	// Requests is the number of conversions the budget let through
+	Requests int64 `json:"requests"`
+	// Rejected counts refused conversions by reason
+	Rejected map[BudgetReason]int64 `json:"rejected,omitempty"`
+	// Callers is the number of callers with a partly used rate limit
+	Callers int `json:"callers"`
+	// Exhausted reports whether a daily ceiling is spent
+	Exhausted bool `json:"exhausted"`
+}
+
+// maxIdleCallers is the number of caller buckets kept before full ones are dropped
+const maxIdleCallers = 10000
+
+// Budget limits the AI requests of one or more mappers: the request rate per
+// provider and per caller, and the tokens and cost spent per day. Spending is
+// counted from the usage providers report, so a request that starts before a
+// ceiling is reached may overshoot it. Budget is safe for concurrent use.
+type Budget struct {
+	providerRate  float64
+	providerBurst int
+	callerRate    float64
+	callerBurst   int
+	prices        map[string]Price
+	policy        ExhaustionPolicy
+	now           func() time.Time
+
+	mu      sync.Mutex
+	state   BudgetState
+	callers map[string]*tokenBucket
+}
+
+// NewBudget creates a budget. Without options it lets every request through
+// and only counts the spending.
+func NewBudget(options ...BudgetOption) *Budget {
+	b := &Budget{
+		prices:  make(map[string]Price),
+		now:     time.Now,
+		callers: make(map[string]*tokenBucket),
+	}
+
+	// Apply all options
+	for _, option := range options {
+		option(b)
+	}
+
+	return b
+}
+
+// WithBudget applies a budget to the AI requests of the mapper. A budget can
+// be shared by several mappers, only provider rate limits are per mapper.
+func WithBudget(budget *Budget) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.budget = budget
+	}
+}
+
+// Allow admits a conversion through the AI, or returns a *BudgetError
+func (b *Budget) Allow(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.rollover(now)
+
+	reason, caller, wait := b.check(ctx, now)
+	if reason != "" {
+		if b.state.Rejected == nil {
+			b.state.Rejected = make(map[BudgetReason]int64)
+		}
+		b.state.Rejected[reason]++
+		return &BudgetError{Reason: reason, Caller: caller, RetryAfter: wait}
+	}
+
+	b.state.Requests++
+	return nil
+}
+
+// check finds the limit refusing a request, spending a caller token otherwise
+func (b *Budget) check(ctx context.Context, now time.Time) (BudgetReason, string, time.Duration) {
+	if b.state.TokenLimit > 0 && b.state.Tokens >= b.state.TokenLimit {
+		return BudgetDailyTokens, "", b.untilTomorrow(now)
+	}
+	if b.state.CostLimit > 0 && b.state.Cost >= b.state.CostLimit {
+		return BudgetDailyCost, "", b.untilTomorrow(now)
+	}
+
+	if b.callerRate <= 0 {
+		return "", "", 0
+	}
+
+	caller := CallerFromContext(ctx)
+	bucket, ok := b.callers[caller]
+	if !ok {
+		if len(b.callers) >= maxIdleCallers {
+			b.forgetIdleCallers(now)
+		}
+		bucket = newTokenBucket(b.callerRate, max(b.callerBurst, 1), now)
+		b.callers[caller] = bucket
+	}
+	if ok, wait := bucket.take(now); !ok {
+		return BudgetCallerRate, caller, wait
+	}
+	return "", "", 0
+}
+
+// Charge adds the usage of a model to the daily spending
+func (b *Budget) Charge(model string, usage Usage) {
+	tokens := usage.TotalTokens
+	if tokens == 0 {
+		tokens = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	price, ok := b.prices[model]
+	if !ok {
+		price = b.prices[""]
+	}
+	cost := (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1e6
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollover(b.now())
+	b.state.Tokens += int64(tokens)
+	b.state.Cost += cost
+}
+
+// State returns a snapshot of the budget
+func (b *Budget) State() BudgetState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.rollover(now)
+	b.forgetIdleCallers(now)
+
+	state := b.state
+	state.Rejected = make(map[BudgetReason]int64, len(b.state.Rejected))
+	for reason, n := range b.state.Rejected {
+		state.Rejected[reason] = n
+	}
+	state.Callers = len(b.callers)
+	state.Exhausted = (state.TokenLimit > 0 && state.Tokens >= state.TokenLimit) ||
+		(state.CostLimit > 0 && state.Cost >= state.CostLimit)
+	return state
+}
+
+// rollover starts a new day of spending
+func (b *Budget) rollover(now time.Time) {
+	day := now.UTC().Format(time.DateOnly)
+	if b.state.Day == day {
+		return
+	}
+	b.state.Day = day
+	b.state.Tokens = 0
+	b.state.Cost = 0
+	b.state.Requests = 0
+	b.state.Rejected = nil
+}
+
+// untilTomorrow is the time left until the daily ceilings reset
+func (b *Budget) untilTomorrow(now time.Time) time.Duration {
+	now = now.UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return tomorrow.Sub(now)
+}
+
+// forgetIdleCallers drops callers whose rate limit refilled completely
+func (b *Budget) forgetIdleCallers(now time.Time) {
+	for caller, bucket := range b.callers {
+		if bucket.full(now) {
+			delete(b.callers, caller)
+		}
+	}
+}
+
+// meteredProvider charges the usage of every answer to a budget
+type meteredProvider struct {
+	provider ScheduleProvider
+	budget   *Budget
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (p *meteredProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	resp, err := p.provider.GenerateSchedule(ctx, req)
+	if resp != nil {
+		p.budget.Charge(resp.Model, resp.Usage)
+	}
+	return resp, err
+}
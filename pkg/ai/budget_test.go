@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// fakeClock is a settable time source
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestRateLimitedProvider(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	limited := NewRateLimitedProvider(&answersProvider{answers: []string{"0 9 * * *"}}, 1, 2)
+	limited.now = clock.now
+	limited.bucket.last = clock.t
+
+	ctx := context.Background()
+	for range 2 {
+		if _, err := limited.GenerateSchedule(ctx, ScheduleRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := limited.GenerateSchedule(ctx, ScheduleRequest{})
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Reason != BudgetProviderRate || budgetErr.RetryAfter != time.Second {
+		t.Fatalf("error = %v, want provider rate limit with retry after 1s", err)
+	}
+
+	clock.t = clock.t.Add(time.Second)
+	if _, err := limited.GenerateSchedule(ctx, ScheduleRequest{}); err != nil {
+		t.Errorf("token not refilled: %v", err)
+	}
+
+	if stats := limited.Stats(); stats.Calls != 4 || stats.Rejected != 1 || stats.Tokens != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestProviderRateLimitFallsBack(t *testing.T) {
+	primary := &answersProvider{answers: []string{"0 9 * * *"}}
+	fallback := &answersProvider{answers: []string{"0 10 * * *"}}
+
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(primary),
+		WithFallbackProviders(fallback),
+		WithBudget(NewBudget(WithRateLimit(0.001, 1))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"0 9 * * *", "0 10 * * *"} {
+		if got, err := mapper.ToCron("business mornings"); err != nil || got != want {
+			t.Errorf("ToCron() = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	stats := mapper.Stats()
+	if stats.Providers[0].RateLimit.Rejected != 1 || stats.Providers[1].RateLimit.Rejected != 0 {
+		t.Errorf("stats = %+v, %+v", stats.Providers[0].RateLimit, stats.Providers[1].RateLimit)
+	}
+}
+
+func TestBudgetDailyCeilings(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)}
+	budget := NewBudget(
+		WithDailyTokens(20),
+		WithDailyCost(1),
+		WithPrice("", 10_000, 20_000),
+	)
+	budget.now = clock.now
+
+	// Every answer reports 10 prompt and 2 completion tokens
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(&answersProvider{answers: []string{"0 9 * * 1-5"}}),
+		WithBudget(budget),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if _, err := mapper.ToCron("business mornings"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = mapper.ToCron("business mornings")
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Reason != BudgetDailyTokens || budgetErr.RetryAfter != time.Hour {
+		t.Fatalf("error = %v, want daily tokens exceeded", err)
+	}
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Error("BudgetError does not match ErrBudgetExceeded")
+	}
+
+	state := mapper.Budget().State()
+	if state.Day != "2024-03-01" || state.Tokens != 24 || state.Requests != 2 || state.Rejected[BudgetDailyTokens] != 1 || !state.Exhausted {
+		t.Errorf("state = %+v", state)
+	}
+	// 20 prompt tokens at 10000 and 4 completion tokens at 20000 per million
+	if math.Abs(state.Cost-0.28) > 1e-9 {
+		t.Errorf("cost = %v, want 0.28", state.Cost)
+	}
+
+	// Rules keep working while the budget is spent
+	if _, err := mapper.ToCron("every day at 9:30"); err != nil {
+		t.Errorf("rules conversion failed: %v", err)
+	}
+
+	clock.t = clock.t.Add(time.Hour)
+	if _, err := mapper.ToCron("business mornings"); err != nil {
+		t.Errorf("budget not reset on a new day: %v", err)
+	}
+	if state := budget.State(); state.Day != "2024-03-02" || state.Tokens != 12 || state.Exhausted {
+		t.Errorf("state = %+v", state)
+	}
+
+	// The cost ceiling applies on its own
+	budget = NewBudget(WithDailyCost(0.1), WithPrice("", 10_000, 0))
+	budget.Charge("any", Usage{PromptTokens: 10})
+	if err := budget.Allow(context.Background()); !errors.As(err, &budgetErr) || budgetErr.Reason != BudgetDailyCost {
+		t.Errorf("error = %v, want daily cost exceeded", err)
+	}
+}
+
+func TestBudgetCallerRateLimit(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	budget := NewBudget(WithCallerRateLimit(0.5, 1))
+	budget.now = clock.now
+
+	alice := ContextWithCaller(context.Background(), "alice")
+	bob := ContextWithCaller(context.Background(), "bob")
+
+	if err := budget.Allow(alice); err != nil {
+		t.Fatal(err)
+	}
+	err := budget.Allow(alice)
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Reason != BudgetCallerRate || budgetErr.Caller != "alice" || budgetErr.RetryAfter != 2*time.Second {
+		t.Fatalf("error = %v, want caller rate limit for alice", err)
+	}
+	if err := budget.Allow(bob); err != nil {
+		t.Errorf("bob limited by alice: %v", err)
+	}
+	if state := budget.State(); state.Callers != 2 || state.Requests != 2 {
+		t.Errorf("state = %+v", state)
+	}
+
+	// Callers whose limit refilled are forgotten
+	clock.t = clock.t.Add(2 * time.Second)
+	if err := budget.Allow(alice); err != nil {
+		t.Errorf("alice not refilled: %v", err)
+	}
+	if state := budget.State(); state.Callers != 1 {
+		t.Errorf("callers = %d, want 1", state.Callers)
+	}
+}
+
+func TestBudgetRulesOnly(t *testing.T) {
+	budget := NewBudget(WithDailyTokens(1), WithExhaustion(RulesOnly))
+	budget.Charge("", Usage{TotalTokens: 1})
+
+	provider := &answersProvider{answers: []string{"0 0 * * *"}}
+	for _, aiFirst := range []bool{false, true} {
+		mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+			WithScheduleProvider(provider),
+			WithAIFirst(aiFirst),
+			WithBudget(budget),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := mapper.ToCron("business mornings"); !errors.Is(err, rules.ErrNoMatch) || errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("aiFirst=%v: error = %v, want the rules error only", aiFirst, err)
+		}
+		if _, err := mapper.AutoDetect("business mornings"); !errors.Is(err, rules.ErrNoMatch) || errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("aiFirst=%v: AutoDetect error = %v, want the rules error only", aiFirst, err)
+		}
+		if got, err := mapper.ToCron("every day at 9:30"); err != nil || got != "30 9 * * *" {
+			t.Errorf("aiFirst=%v: ToCron() = %q, %v", aiFirst, got, err)
+		}
+	}
+
+	if provider.calls != 0 {
+		t.Errorf("provider called %d times on a spent budget", provider.calls)
+	}
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flaticols/cronscribe/pkg/core/cache"
+)
+
+func TestBraveMapperCache(t *testing.T) {
+	provider := &flakyProvider{expression: "Here you go: `0 12 * * 3`"}
+	store, err := cache.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		mapper, err := NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(provider), WithCache(store))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := mapper.ToCronResult(context.Background(), "midweek lunch")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Expression != "0 12 * * 3" || result.Source != SourceAI || result.AI == nil {
+			t.Errorf("unexpected result %+v", result)
+		}
+
+		rules, err := mapper.ToCronResult(context.Background(), "every day at 9:30")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rules.Source != SourceRules || rules.Rules == nil || rules.Rules.Rule == "" {
+			t.Errorf("unexpected rules result %+v", rules)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("provider called %d times, want 1", provider.calls)
+	}
+}
+
+func TestBraveMapperNegativeCache(t *testing.T) {
+	unusable := &flakyProvider{expression: "I am not sure what you mean"}
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(unusable), WithCache(cache.NewMemory(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		_, err := mapper.ToCron("blorp")
+		var extractionErr *ExtractionError
+		if !errors.As(err, &extractionErr) || extractionErr.Response != "I am not sure what you mean" {
+			t.Fatalf("expected extraction error, got %v", err)
+		}
+	}
+	if unusable.calls != 1 {
+		t.Errorf("unusable answer not cached: %d calls", unusable.calls)
+	}
+
+	// Provider errors are not cached
+	failing := &flakyProvider{errs: []error{errUnavailable}, expression: "0 0 * * *"}
+	mapper, err = NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(failing), WithCache(cache.NewMemory(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapper.ToCron("blorp"); !errors.Is(err, errUnavailable) {
+		t.Fatalf("expected provider error, got %v", err)
+	}
+	if expr, err := mapper.ToCron("blorp"); err != nil || expr != "0 0 * * *" {
+		t.Errorf("ToCron = %q, %v", expr, err)
+	}
+}
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ChainStats holds the counters of a ChainProvider
+type ChainStats struct {
+	// Calls is the number of requests received by the chain
+	Calls int64 `json:"calls"`
+	// Failures is the number of requests no provider could serve
+	Failures int64 `json:"failures"`
+	// Fallbacks is the number of requests served by a provider other than the first
+	Fallbacks int64 `json:"fallbacks"`
+	// Served holds the number of requests served by each provider, in chain order
+	Served []int64 `json:"served"`
+}
+
+// ChainProvider tries its providers in order and returns the first answer
+type ChainProvider struct {
+	providers []ScheduleProvider
+
+	mu    sync.Mutex
+	stats ChainStats
+}
+
+// NewChainProvider creates a provider that falls back through the given providers.
+// AIProvider implementations can be added with AdaptProvider.
+func NewChainProvider(providers ...ScheduleProvider) *ChainProvider {
+	return &ChainProvider{
+		providers: providers,
+		stats:     ChainStats{Served: make([]int64, len(providers))},
+	}
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (c *ChainProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	c.mu.Lock()
+	c.stats.Calls++
+	c.mu.Unlock()
+
+	var errs []error
+	for i, provider := range c.providers {
+		resp, err := provider.GenerateSchedule(ctx, req)
+		if err == nil {
+			c.mu.Lock()
+			c.stats.Served[i]++
+			if i > 0 {
+				c.stats.Fallbacks++
+			}
+			c.mu.Unlock()
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("provider %d: %w", i, err))
+
+		// The caller gave up, the remaining providers would fail the same way
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.stats.Failures++
+	c.mu.Unlock()
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("provider chain is empty")
+	}
+	return nil, errors.Join(errs...)
+}
+
+// GenerateCron implements the AIProvider interface
+func (c *ChainProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, c, input)
+}
+
+// Stats returns a snapshot of the chain counters
+func (c *ChainProvider) Stats() ChainStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Served = append([]int64(nil), c.stats.Served...)
+	return stats
+}
@@ -29,19 +29,9 @@ func (c *CronScribeAI) ToCron(expression string) (string, error) {
 
 // WithCore creates a new CronScribeAI instance using an existing core instance
 func WithCore(coreInstance *core.CronScribe, provider AIProvider, options ...BraveOption) (*CronScribeAI, error) {
-	if provider == nil {
-		return nil, nil
-	}
-
-	mapper := &BraveHumanCronMapper{
-		coreMapper: coreInstance,
-		aiProvider: provider,
-		useAIFirst: false, // Default to using local rules first
-	}
-
-	// Apply all options
-	for _, option := range options {
-		option(mapper)
+	mapper, err := newBraveMapper(coreInstance, provider, options...)
+	if err != nil {
+		return nil, err
 	}
 
 	return &CronScribeAI{
@@ -0,0 +1,181 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+// ExtractionError is returned when an AI response contains no valid cron
+// expression. It carries the raw response for logging.
+type ExtractionError struct {
+	// Response is the raw text returned by the provider
+	Response string
+	// Candidates are the expressions found in the response that failed validation
+	Candidates []string
+}
+
+func (e *ExtractionError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("no cron expression found in AI response %q", e.Response)
+	}
+	return fmt.Sprintf("no valid cron expression in AI response %q, rejected %q", e.Response, e.Candidates)
+}
+
+var (
+	fencedBlock = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*\\n?(.*?)```")
+	inlineCode  = regexp.MustCompile("`([^`\\n]+)`")
+	cronToken   = regexp.MustCompile(`^(?:[0-9A-Za-z*?/,#\-]+|(?:CRON_TZ|TZ)=\S+)$`)
+)
+
+// ExtractCron finds the cron expression in a free-form AI response. It looks
+// into fenced code blocks first, then inline code, then the plain text, and
+// validates every candidate with a cron parser. The first valid candidate is
+// returned in its normalized form, in the standard dialect.
+func ExtractCron(response string) (string, error) {
+	return ExtractCronDialect(response, cron.Standard)
+}
+
+// ExtractCronDialect is ExtractCron that writes the expression in the given
+// dialect. Candidates the dialect cannot express are rejected.
+func ExtractCronDialect(response string, dialect cron.Dialect) (string, error) {
+	dialect = orStandard(dialect)
+	var rejected []string
+	seen := make(map[string]bool)
+
+	try := func(candidate string) (string, bool) {
+		candidate = cleanCandidate(candidate)
+		if candidate == "" || seen[candidate] {
+			return "", false
+		}
+		seen[candidate] = true
+
+		expr, err := cron.Parse(candidate)
+		if err != nil {
+			if len(strings.Fields(candidate)) >= 5 {
+				rejected = append(rejected, candidate)
+			}
+			return "", false
+		}
+
+		normalized, err := dialect.Render(expr)
+		if err != nil {
+			rejected = append(rejected, candidate)
+			return "", false
+		}
+		return normalized, true
+	}
+
+	var sources []string
+	for _, m := range fencedBlock.FindAllStringSubmatch(response, -1) {
+		sources = append(sources, m[1])
+	}
+	for _, m := range inlineCode.FindAllStringSubmatch(response, -1) {
+		sources = append(sources, m[1])
+	}
+	sources = append(sources, response)
+
+	for _, source := range sources {
+		if expr, ok := try(source); ok {
+			return expr, nil
+		}
+		for _, line := range strings.Split(source, "\n") {
+			if expr, ok := try(line); ok {
+				return expr, nil
+			}
+		}
+	}
+
+	// Look for a run of exactly five cron-like words anywhere in the prose
+	for _, line := range strings.Split(response, "\n") {
+		tokens := strings.Fields(line)
+		for i := range tokens {
+			tokens[i] = strings.Trim(tokens[i], "`\"'.,;:()[]{}")
+		}
+
+		for start := 0; start < len(tokens); {
+			end := start
+			for end < len(tokens) && isCronToken(tokens[end]) {
+				end++
+			}
+
+			run := tokens[start:end]
+			if len(run) == 5 || (len(run) == 6 && strings.Contains(run[0], "=")) {
+				if expr, ok := try(strings.Join(run, " ")); ok {
+					return expr, nil
+				}
+			} else if len(run) > 5 {
+				rejected = append(rejected, strings.Join(run, " "))
+			}
+			start = end + 1
+		}
+	}
+
+	return "", &ExtractionError{Response: response, Candidates: rejected}
+}
+
+// cleanCandidate strips quotes, labels and punctuation around a candidate
+func cleanCandidate(candidate string) string {
+	candidate = strings.TrimSpace(candidate)
+	if label, rest, ok := strings.Cut(candidate, ":"); ok && !strings.ContainsAny(label, "*/0123456789") {
+		candidate = strings.TrimSpace(rest)
+	}
+	return strings.Trim(candidate, "`\"'. \t\r")
+}
+
+// isCronToken reports whether a word of prose looks like a cron field: it
+// holds a digit or a wildcard, or is a day or month name or L
+func isCronToken(token string) bool {
+	if token == "" || !cronToken.MatchString(token) {
+		return false
+	}
+	return strings.ContainsAny(token, "0123456789*?") || cronWords[strings.ToLower(token)]
+}
+
+var cronWords = map[string]bool{
+	"l": true, "lw": true,
+	"mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true, "sun": true,
+	"jan": true, "feb": true, "mar": true, "apr": true, "may": true, "jun": true,
+	"jul": true, "aug": true, "sep": true, "oct": true, "nov": true, "dec": true,
+}
+
+// ExtractCronStrict accepts a response only when it is a valid cron expression
+// as a whole, optionally wrapped in quotes or a code fence, and returns it in
+// its normalized form, in the standard dialect
+func ExtractCronStrict(response string) (string, error) {
+	return ExtractCronStrictDialect(response, cron.Standard)
+}
+
+// ExtractCronStrictDialect is ExtractCronStrict that writes the expression in
+// the given dialect
+func ExtractCronStrictDialect(response string, dialect cron.Dialect) (string, error) {
+	candidate := strings.TrimSpace(response)
+	if m := fencedBlock.FindStringSubmatch(candidate); m != nil && m[0] == candidate {
+		candidate = m[1]
+	}
+	candidate = strings.TrimSpace(strings.Trim(strings.TrimSpace(candidate), "`\"'"))
+
+	if candidate == "" {
+		return "", &ExtractionError{Response: response}
+	}
+
+	expr, err := cron.Parse(candidate)
+	if err != nil {
+		return "", &ExtractionError{Response: response, Candidates: []string{candidate}}
+	}
+	normalized, err := orStandard(dialect).Render(expr)
+	if err != nil {
+		return "", &ExtractionError{Response: response, Candidates: []string{candidate}}
+	}
+	return normalized, nil
+}
+
+// orStandard returns the dialect, or the standard one for the zero value
+func orStandard(dialect cron.Dialect) cron.Dialect {
+	if dialect.Name == "" {
+		return cron.Standard
+	}
+	return dialect
+}
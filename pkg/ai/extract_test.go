@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractCron(t *testing.T) {
+	tests := map[string]string{
+		"0 9 * * 1\n":                                      "0 9 * * 1",
+		`"0 9 * * 1"`:                                      "0 9 * * 1",
+		"```cron\n0 9 * * MON\n```":                        "0 9 * * 1",
+		"```\n# every monday\n0 9 * * 1\n```":              "0 9 * * 1",
+		"Use `*/15 * * * *` for that.":                     "*/15 * * * *",
+		"Here is your cron: 30 14 1 * *. Enjoy!":           "30 14 1 * *",
+		"Cron expression: 0 0 L * *":                       "0 0 L * *",
+		"Try 0 0 L * * for month ends":                     "0 0 L * *",
+		"The schedule is\n\n0 22 * * 1-5\n\nwhich runs...": "0 22 * * 1-5",
+		"CRON_TZ=Europe/Amsterdam 0 9 * * *":               "CRON_TZ=Europe/Amsterdam 0 9 * * *",
+		"```\n0 0 9 * * 1\n```":                            "0 9 * * 1",
+	}
+
+	for response, want := range tests {
+		got, err := ExtractCron(response)
+		if err != nil {
+			t.Errorf("ExtractCron(%q) error = %v", response, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ExtractCron(%q) = %q, want %q", response, got, want)
+		}
+	}
+}
+
+func TestExtractCronError(t *testing.T) {
+	for _, response := range []string{"", "I cannot help with that.", "0 25 * * *", "```\n0 0 0 0 * * 1\n```", "```\n30 0 9 * * 1\n```"} {
+		_, err := ExtractCron(response)
+
+		var extractionErr *ExtractionError
+		if !errors.As(err, &extractionErr) {
+			t.Errorf("ExtractCron(%q) error = %v, want *ExtractionError", response, err)
+			continue
+		}
+		if extractionErr.Response != response {
+			t.Errorf("ExtractionError.Response = %q, want %q", extractionErr.Response, response)
+		}
+	}
+}
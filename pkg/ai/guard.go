@@ -0,0 +1,163 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+// RejectReason tells why an input was not sent to the AI provider
+type RejectReason string
+
+const (
+	// RejectEmpty is reported for inputs without any text
+	RejectEmpty RejectReason = "empty"
+	// RejectTooLong is reported for inputs longer than the maximum length
+	RejectTooLong RejectReason = "too_long"
+	// RejectControlCharacters is reported for inputs with control, zero-width
+	// or bidirectional formatting characters
+	RejectControlCharacters RejectReason = "control_characters"
+	// RejectInstructions is reported for inputs that look like instructions to the model
+	RejectInstructions RejectReason = "instructions"
+)
+
+// InputError is returned when an input is rejected before it reaches the AI provider
+type InputError struct {
+	Reason RejectReason
+	// Detail describes what was found, it does not repeat the input
+	Detail string
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("input rejected (%s): %s", e.Reason, e.Detail)
+}
+
+// DefaultMaxInputLength is the maximum input length in characters
+const DefaultMaxInputLength = 256
+
+// instructionPattern matches one kind of prompt injection phrasing
+type instructionPattern struct {
+	// kind names what the pattern looks for, errors report it instead of the match
+	kind    string
+	pattern *regexp.Regexp
+}
+
+// instructionPatterns match common prompt injection phrasing
+var instructionPatterns = []instructionPattern{
+	{"a request to ignore earlier instructions", regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|bypass)\b.{0,40}\b(?:instructions?|prompts?|rules|above|previous|earlier|system)\b`)},
+	{"a reference to the system prompt", regexp.MustCompile(`(?i)\b(?:system|developer|hidden)\s+(?:prompt|message|instructions?)\b`)},
+	{"a role change", regexp.MustCompile(`(?i)\byou\s+are\s+(?:now|no\s+longer)\b`)},
+	{"a role change", regexp.MustCompile(`(?i)\b(?:act|behave|pretend)\s+(?:as|like)\b`)},
+	{"a dictated answer", regexp.MustCompile(`(?i)\b(?:respond|reply|answer|output|print|return|say)\s+(?:only\s+)?(?:with|the\s+following|exactly)\b`)},
+	{"a chat role marker", regexp.MustCompile(`(?i)(?:^|\s)(?:system|assistant|user)\s*:`)},
+	{"a code fence or chat template markup", regexp.MustCompile("```|<\\|[a-z_]*\\|?>?|\\[/?INST\\]|</?(?:system|assistant|user)>")},
+	{"a request to ignore earlier instructions", regexp.MustCompile(`(?i)(?:игнорируй|проигнорируй|забудь|не\s+обращай\s+внимания).{0,40}(?:инструкци|правил|выше|предыдущ)`)},
+	{"a role change", regexp.MustCompile(`(?i)ты\s+теперь`)},
+	{"a reference to the system prompt", regexp.MustCompile(`(?i)системн(?:ый|ое)\s+(?:промпт|сообщение)`)},
+	{"a request to ignore earlier instructions", regexp.MustCompile(`(?i)\b(?:negeer|vergeet)\b.{0,40}\b(?:instructies?|regels|hierboven|vorige)\b`)},
+}
+
+// GuardOption represents a functional option for configuring InputGuard
+type GuardOption func(*InputGuard)
+
+// WithMaxInputLength sets the maximum input length in characters
+func WithMaxInputLength(length int) GuardOption {
+	return func(g *InputGuard) {
+		g.maxLength = length
+	}
+}
+
+// WithInstructionPatterns adds patterns of instruction-like content to reject.
+// Inputs they match are reported as matching a custom pattern.
+func WithInstructionPatterns(patterns ...*regexp.Regexp) GuardOption {
+	return func(g *InputGuard) {
+		for _, pattern := range patterns {
+			g.patterns = append(g.patterns, instructionPattern{kind: "text matching a custom pattern", pattern: pattern})
+		}
+	}
+}
+
+// WithStrictOutput only accepts answers that are a cron expression and
+// nothing else, apart from surrounding whitespace, quotes or a code fence
+func WithStrictOutput(strict bool) GuardOption {
+	return func(g *InputGuard) {
+		g.strictOutput = strict
+	}
+}
+
+// InputGuard checks inputs before they are sent to the AI provider and the
+// answers that come back
+type InputGuard struct {
+	maxLength    int
+	patterns     []instructionPattern
+	strictOutput bool
+}
+
+// NewInputGuard creates a guard with the default length limit and instruction patterns
+func NewInputGuard(options ...GuardOption) *InputGuard {
+	g := &InputGuard{
+		maxLength: DefaultMaxInputLength,
+		patterns:  append([]instructionPattern(nil), instructionPatterns...),
+	}
+
+	// Apply all options
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// WithInputGuard replaces the default input guard of the AI path
+func WithInputGuard(options ...GuardOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.guard = NewInputGuard(options...)
+	}
+}
+
+// Check validates an input and returns it with line breaks and tabs folded
+// into single spaces, or an InputError
+func (g *InputGuard) Check(input string) (string, error) {
+	if !utf8.ValidString(input) {
+		return "", &InputError{Reason: RejectControlCharacters, Detail: "input is not valid UTF-8"}
+	}
+
+	for _, r := range input {
+		if r == '\n' || r == '\r' || r == '\t' {
+			continue
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return "", &InputError{Reason: RejectControlCharacters, Detail: fmt.Sprintf("input contains character %U", r)}
+		}
+	}
+
+	cleaned := strings.Join(strings.Fields(input), " ")
+	if cleaned == "" {
+		return "", &InputError{Reason: RejectEmpty, Detail: "input is empty"}
+	}
+
+	if length := utf8.RuneCountInString(cleaned); g.maxLength > 0 && length > g.maxLength {
+		return "", &InputError{Reason: RejectTooLong, Detail: fmt.Sprintf("input has %d characters, the maximum is %d", length, g.maxLength)}
+	}
+
+	for _, p := range g.patterns {
+		if p.pattern.MatchString(cleaned) {
+			return "", &InputError{Reason: RejectInstructions, Detail: "input contains " + p.kind}
+		}
+	}
+
+	return cleaned, nil
+}
+
+// Output extracts the cron expression from an answer, strictly when
+// configured, and writes it in the dialect
+func (g *InputGuard) Output(response string, dialect cron.Dialect) (string, error) {
+	if g.strictOutput {
+		return ExtractCronStrictDialect(response, dialect)
+	}
+	return ExtractCronDialect(response, dialect)
+}
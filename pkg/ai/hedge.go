@@ -0,0 +1,177 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HedgePolicy decides which answer a hedged conversion returns
+type HedgePolicy int
+
+const (
+	// HedgeFirstValid returns the first valid answer, from the rules or the AI
+	HedgeFirstValid HedgePolicy = iota
+	// HedgePreferRules returns the rules answer when it arrives within the
+	// grace period, and the first valid answer after it
+	HedgePreferRules
+	// HedgePreferAI returns the AI answer when it arrives within the AI
+	// deadline, and the first valid answer after it
+	HedgePreferAI
+)
+
+// String returns the name of the policy
+func (p HedgePolicy) String() string {
+	switch p {
+	case HedgePreferRules:
+		return "prefer-rules"
+	case HedgePreferAI:
+		return "prefer-ai"
+	default:
+		return "first-valid"
+	}
+}
+
+// HedgeOption represents a functional option for configuring hedged conversions
+type HedgeOption func(*hedge)
+
+// WithGracePeriod sets how long HedgePreferRules waits for the rules, 10ms by default
+func WithGracePeriod(grace time.Duration) HedgeOption {
+	return func(h *hedge) {
+		h.grace = grace
+	}
+}
+
+// WithAIDeadline sets how long HedgePreferAI waits for the AI, 2s by default
+func WithAIDeadline(deadline time.Duration) HedgeOption {
+	return func(h *hedge) {
+		h.deadline = deadline
+	}
+}
+
+// WithHedging starts the rules and the AI concurrently for every conversion,
+// so callers neither wait for the AI when the rules can answer nor for the
+// rules to fail before the AI starts. The losing conversion is cancelled
+// through its context. Hedging takes precedence over WithAIFirst and
+// WithRouting, shadow mode over hedging.
+func WithHedging(policy HedgePolicy, options ...HedgeOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		h := &hedge{policy: policy, grace: 10 * time.Millisecond, deadline: 2 * time.Second}
+		for _, option := range options {
+			option(h)
+		}
+		m.hedge = h
+	}
+}
+
+// hedge holds the hedging policy
+type hedge struct {
+	policy   HedgePolicy
+	grace    time.Duration
+	deadline time.Duration
+}
+
+// preferred returns the source a policy waits for and how long it waits
+func (h *hedge) preferred() (Source, time.Duration) {
+	switch h.policy {
+	case HedgePreferRules:
+		return SourceRules, h.grace
+	case HedgePreferAI:
+		return SourceAI, h.deadline
+	default:
+		return "", 0
+	}
+}
+
+// String describes the policy, for cache keys
+func (h *hedge) String() string {
+	return fmt.Sprintf("hedge:%s:%s:%s", h.policy, h.grace, h.deadline)
+}
+
+// hedgeOutcome is the answer of one side of a hedged conversion
+type hedgeOutcome struct {
+	source Source
+	result *Result
+	err    error
+}
+
+// hedged runs the rules and the AI concurrently and picks an answer by policy.
+// Both goroutines send to a buffered channel, so they finish even when their
+// answer is no longer read, and the AI is cancelled once an answer is picked.
+func (m *BraveHumanCronMapper) hedged(ctx context.Context, expression string, autoDetect bool) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	convert := m.coreMapper.ConvertResult
+	if autoDetect {
+		convert = m.coreMapper.AutoDetectResult
+	}
+	// The AI does not get the rules error, as the rules run at the same time
+	req := m.request(expression, autoDetect, nil)
+
+	outcomes := make(chan hedgeOutcome, 2)
+	go func() {
+		result, err := convert(expression)
+		if err != nil {
+			outcomes <- hedgeOutcome{source: SourceRules, err: err}
+			return
+		}
+		outcomes <- hedgeOutcome{source: SourceRules, result: rulesOutcome(result)}
+	}()
+	go func() {
+		result, err := m.generate(ctx, req)
+		outcomes <- hedgeOutcome{source: SourceAI, result: result, err: err}
+	}()
+
+	preferred, window := m.hedge.preferred()
+	var windowEnd <-chan time.Time
+	if preferred != "" {
+		timer := time.NewTimer(window)
+		defer timer.Stop()
+		windowEnd = timer.C
+	}
+
+	// held is a valid answer of the other side, kept while the window is open
+	var held *hedgeOutcome
+	var rulesErr, aiErr error
+	preferredFailed := false
+	for pending := 2; pending > 0; {
+		select {
+		case o := <-outcomes:
+			pending--
+			if o.err != nil {
+				if o.source == SourceRules {
+					rulesErr = o.err
+				} else {
+					aiErr = o.err
+				}
+				if o.source == preferred {
+					preferredFailed = true
+				}
+				if held != nil {
+					return held.result, nil
+				}
+				continue
+			}
+			if windowEnd == nil || o.source == preferred || preferredFailed {
+				return o.result, nil
+			}
+			held = &o
+
+		case <-windowEnd:
+			windowEnd = nil
+			if held != nil {
+				return held.result, nil
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if m.rulesOnly(aiErr) {
+		return nil, rulesErr
+	}
+	return nil, fmt.Errorf("unable to convert expression with local rules or AI: %s: %w", expression, errors.Join(rulesErr, aiErr))
+}
@@ -0,0 +1,147 @@
+package ai_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// hedgeProvider answers after a delay and reports when it is cancelled
+type hedgeProvider struct {
+	delay     time.Duration
+	answer    string
+	err       error
+	cancelled chan struct{}
+}
+
+func (p *hedgeProvider) GenerateSchedule(ctx context.Context, _ ai.ScheduleRequest) (*ai.ScheduleResponse, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		close(p.cancelled)
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ai.ScheduleResponse{Expression: p.answer}, nil
+}
+
+// checkGoroutines fails the test when goroutines started after the baseline
+// are still running once things had time to settle
+func checkGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines leaked:\n%s", runtime.NumGoroutine()-baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHedging(t *testing.T) {
+	const slow = time.Minute
+
+	tests := []struct {
+		name      string
+		policy    ai.HedgePolicy
+		input     string
+		provider  *hedgeProvider
+		want      string
+		source    ai.Source
+		cancelled bool
+	}{
+		{"first valid, rules win", ai.HedgeFirstValid, "every day at 9:30", &hedgeProvider{delay: slow}, "30 9 * * *", ai.SourceRules, true},
+		{"first valid, ai wins", ai.HedgeFirstValid, "business mornings", &hedgeProvider{delay: 10 * time.Millisecond, answer: "0 9 * * 1-5"}, "0 9 * * 1-5", ai.SourceAI, false},
+		{"prefer rules, rules match", ai.HedgePreferRules, "every day at 9:30", &hedgeProvider{answer: "0 0 * * *"}, "30 9 * * *", ai.SourceRules, false},
+		{"prefer rules, rules fail", ai.HedgePreferRules, "business mornings", &hedgeProvider{answer: "0 9 * * 1-5"}, "0 9 * * 1-5", ai.SourceAI, false},
+		{"prefer ai within deadline", ai.HedgePreferAI, "every day at 9:30", &hedgeProvider{delay: 10 * time.Millisecond, answer: "0 0 * * *"}, "0 0 * * *", ai.SourceAI, false},
+		{"prefer ai past deadline", ai.HedgePreferAI, "every day at 9:30", &hedgeProvider{delay: slow}, "30 9 * * *", ai.SourceRules, true},
+		{"prefer ai, ai fails", ai.HedgePreferAI, "every day at 9:30", &hedgeProvider{err: errOffline}, "30 9 * * *", ai.SourceRules, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseline := runtime.NumGoroutine()
+			tt.provider.cancelled = make(chan struct{})
+
+			mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil,
+				ai.WithScheduleProvider(tt.provider),
+				ai.WithHedging(tt.policy, ai.WithAIDeadline(200*time.Millisecond), ai.WithGracePeriod(200*time.Millisecond)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			result, err := mapper.ToCronResult(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Expression != tt.want || result.Source != tt.source {
+				t.Errorf("result = %s from %s, want %s from %s", result.Expression, result.Source, tt.want, tt.source)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("conversion took %s", elapsed)
+			}
+
+			if tt.cancelled {
+				select {
+				case <-tt.provider.cancelled:
+				case <-time.After(time.Second):
+					t.Error("losing AI request not cancelled")
+				}
+			}
+			checkGoroutines(t, baseline)
+		})
+	}
+}
+
+func TestHedgingFailures(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	provider := &hedgeProvider{delay: 5 * time.Millisecond, err: errOffline, cancelled: make(chan struct{})}
+	mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil,
+		ai.WithScheduleProvider(provider),
+		ai.WithHedging(ai.HedgePreferRules),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = mapper.AutoDetectResult(context.Background(), "business mornings")
+	if !errors.Is(err, errOffline) || !errors.Is(err, rules.ErrNoMatch) {
+		t.Errorf("error = %v, want both failures", err)
+	}
+
+	// The caller giving up cancels the AI
+	provider = &hedgeProvider{delay: time.Minute, cancelled: make(chan struct{})}
+	mapper, err = ai.NewBraveHumanCronMapper("../core/rules", nil,
+		ai.WithScheduleProvider(provider),
+		ai.WithHedging(ai.HedgeFirstValid),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := mapper.ToCronResult(ctx, "business mornings"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want deadline exceeded", err)
+	}
+	select {
+	case <-provider.cancelled:
+	case <-time.After(time.Second):
+		t.Error("AI request not cancelled")
+	}
+
+	checkGoroutines(t, baseline)
+}
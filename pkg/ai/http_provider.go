@@ -0,0 +1,309 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxResponseSize is the largest API response the HTTP providers read, in bytes
+const DefaultMaxResponseSize = 1 << 20
+
+// ErrResponseTooLarge is returned when an API response exceeds the maximum size
+var ErrResponseTooLarge = errors.New("AI API response too large")
+
+// ProviderOption represents a functional option for configuring the HTTP providers
+type ProviderOption func(*httpConfig)
+
+// PromptFunc builds the system and user prompts for a request, such as Prompts.Render
+type PromptFunc func(req ScheduleRequest) (system, user string, err error)
+
+// httpConfig holds the settings shared by the HTTP providers
+type httpConfig struct {
+	baseURL     string
+	model       string
+	apiKey      string
+	headers     http.Header
+	temperature float64
+	client      *http.Client
+	prompt      PromptFunc
+	structured  bool
+	maxResponse int64
+}
+
+// WithBaseURL sets the base URL of the API
+func WithBaseURL(baseURL string) ProviderOption {
+	return func(c *httpConfig) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithModel sets the model name sent with every request
+func WithModel(model string) ProviderOption {
+	return func(c *httpConfig) {
+		c.model = model
+	}
+}
+
+// WithAPIKey sets the key sent as a bearer token
+func WithAPIKey(apiKey string) ProviderOption {
+	return func(c *httpConfig) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithHeader adds a header sent with every request
+func WithHeader(name, value string) ProviderOption {
+	return func(c *httpConfig) {
+		c.headers.Add(name, value)
+	}
+}
+
+// WithTemperature sets the sampling temperature, 0 by default
+func WithTemperature(temperature float64) ProviderOption {
+	return func(c *httpConfig) {
+		c.temperature = temperature
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(client *http.Client) ProviderOption {
+	return func(c *httpConfig) {
+		c.client = client
+	}
+}
+
+// WithMaxResponseSize sets the largest response read from the API, in bytes,
+// DefaultMaxResponseSize by default
+func WithMaxResponseSize(size int64) ProviderOption {
+	return func(c *httpConfig) {
+		c.maxResponse = size
+	}
+}
+
+// WithPrompts renders prompts with the given templates
+func WithPrompts(prompts *Prompts) ProviderOption {
+	return func(c *httpConfig) {
+		c.prompt = prompts.Render
+	}
+}
+
+// WithPromptFunc replaces the prompt templates with a function
+func WithPromptFunc(prompt PromptFunc) ProviderOption {
+	return func(c *httpConfig) {
+		c.prompt = prompt
+	}
+}
+
+// WithStructuredOutput asks the model for a StructuredSchedule instead of cron
+// text, through function calling with OpenAI and a format schema with Ollama.
+// The provider validates the answer and renders it in the request's dialect.
+func WithStructuredOutput() ProviderOption {
+	return func(c *httpConfig) {
+		c.structured = true
+	}
+}
+
+func newHTTPConfig(baseURL, model string, options []ProviderOption) *httpConfig {
+	c := &httpConfig{
+		baseURL:     baseURL,
+		model:       model,
+		headers:     make(http.Header),
+		client:      http.DefaultClient,
+		prompt:      DefaultPrompts().Render,
+		maxResponse: DefaultMaxResponseSize,
+	}
+
+	// Apply all options
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// HTTPError is returned when an API answers with a non-success status
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("AI API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether the request may succeed when sent again: timeouts,
+// rate limits and server errors
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// chatMessage is a message of the OpenAI and Ollama chat APIs
+type chatMessage struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+}
+
+// toolCall is a function call requested by the model. OpenAI sends the
+// arguments as a JSON string, Ollama as an object.
+type toolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// chatTool declares a function the model may call
+type chatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Parameters  map[string]any `json:"parameters"`
+	} `json:"function"`
+}
+
+// structuredTool declares the set_schedule function
+func structuredTool() chatTool {
+	tool := chatTool{Type: "function"}
+	tool.Function.Name = StructuredToolName
+	tool.Function.Description = "Set the schedule described by the user"
+	tool.Function.Parameters = StructuredScheduleSchema()
+	return tool
+}
+
+// messages builds the chat messages for a request
+func (c *httpConfig) messages(req ScheduleRequest) ([]chatMessage, error) {
+	system, user, err := c.prompt(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.structured {
+		system += "\nDo not write cron syntax: describe the schedule field by field as JSON, through the " + StructuredToolName + " function when it is offered."
+	}
+	return []chatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: user},
+	}, nil
+}
+
+// structuredResponse reads the structured schedule from the model's tool calls
+// or, when the model ignored the tools, from its text, and renders it in the
+// request's dialect. Answers without a structured schedule fall back to the
+// cron text they contain.
+func structuredResponse(message chatMessage, req ScheduleRequest, resp *ScheduleResponse) error {
+	text := message.Content
+	for _, call := range message.ToolCalls {
+		if call.Function.Name != StructuredToolName {
+			continue
+		}
+		text = string(call.Function.Arguments)
+		var encoded string
+		if json.Unmarshal(call.Function.Arguments, &encoded) == nil {
+			text = encoded
+		}
+		break
+	}
+
+	schedule, err := ParseStructuredSchedule(text)
+	var invalid *StructuredError
+	if errors.As(err, &invalid) {
+		return err
+	}
+	if err != nil {
+		if expr, cronErr := ExtractCronDialect(text, req.Dialect); cronErr == nil {
+			resp.Expression = expr
+			return nil
+		}
+		return &ExtractionError{Response: text}
+	}
+
+	expr, err := schedule.Expression()
+	if err != nil {
+		return err
+	}
+	if expr.Location == nil {
+		expr.Location = req.Location
+	}
+
+	rendered, err := orStandard(req.Dialect).Render(expr)
+	if err != nil {
+		return err
+	}
+
+	resp.Expression = rendered
+	resp.Structured = schedule
+	return nil
+}
+
+// post sends a JSON request and decodes the JSON response
+func (c *httpConfig) post(ctx context.Context, path string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	for name, values := range c.headers {
+		for _, value := range values {
+			httpReq.Header.Add(name, value)
+		}
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("AI API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// One byte past the limit tells a response of exactly the limit from a larger one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponse+1))
+	if err != nil {
+		return fmt.Errorf("failed to read AI API response: %w", err)
+	}
+	if int64(len(data)) > c.maxResponse {
+		return fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, c.maxResponse)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &HTTPError{StatusCode: resp.StatusCode, Message: errorMessage(data)}
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode AI API response: %w", err)
+	}
+	return nil
+}
+
+// errorMessage extracts the message of an error response body
+func errorMessage(body []byte) string {
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) == nil && len(payload.Error) > 0 {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(payload.Error, &nested) == nil && nested.Message != "" {
+			return nested.Message
+		}
+		var text string
+		if json.Unmarshal(payload.Error, &text) == nil && text != "" {
+			return text
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
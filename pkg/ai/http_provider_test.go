@@ -0,0 +1,212 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubServer records the last request body and answers with a fixed status and body
+func stubServer(t *testing.T, path string, status int, response string, body *map[string]any, header *http.Header) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != path {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if body != nil {
+			if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+		}
+		if header != nil {
+			*header = r.Header.Clone()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	var body map[string]any
+	var header http.Header
+	server := stubServer(t, "/v1/chat/completions", http.StatusOK, `{
+		"model": "gpt-test-2024",
+		"choices": [{"message": {"role": "assistant", "content": "0 9 * * 1-5"}}],
+		"usage": {"prompt_tokens": 40, "completion_tokens": 6, "total_tokens": 46}
+	}`, &body, &header)
+
+	provider := NewOpenAIProvider(
+		WithBaseURL(server.URL+"/v1/"),
+		WithModel("gpt-test"),
+		WithAPIKey("secret"),
+		WithHeader("X-Org", "acme"),
+		WithTemperature(0.2),
+	)
+
+	resp, err := provider.GenerateSchedule(context.Background(), ScheduleRequest{Input: "weekdays at nine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Expression != "0 9 * * 1-5" || resp.Model != "gpt-test-2024" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if resp.Usage != (Usage{PromptTokens: 40, CompletionTokens: 6, TotalTokens: 46}) {
+		t.Errorf("unexpected usage %+v", resp.Usage)
+	}
+
+	if got := header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := header.Get("X-Org"); got != "acme" {
+		t.Errorf("X-Org = %q", got)
+	}
+	if body["model"] != "gpt-test" || body["temperature"] != 0.2 {
+		t.Errorf("unexpected request %v", body)
+	}
+
+	wantSystem, wantUser, err := DefaultPrompts().Render(ScheduleRequest{Input: "weekdays at nine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := body["messages"].([]any)
+	system := messages[0].(map[string]any)
+	user := messages[1].(map[string]any)
+	if system["role"] != "system" || system["content"] != wantSystem {
+		t.Errorf("unexpected system message %v", system)
+	}
+	if user["role"] != "user" || user["content"] != wantUser {
+		t.Errorf("unexpected user message %v", user)
+	}
+}
+
+func TestOllamaProvider(t *testing.T) {
+	var body map[string]any
+	var header http.Header
+	server := stubServer(t, "/api/chat", http.StatusOK, `{
+		"model": "llama-test",
+		"message": {"role": "assistant", "content": "30 6 * * *"},
+		"done": true,
+		"prompt_eval_count": 52,
+		"eval_count": 8
+	}`, &body, &header)
+
+	provider := NewOllamaProvider(
+		WithBaseURL(server.URL),
+		WithModel("llama-test"),
+		WithPromptFunc(func(req ScheduleRequest) (string, string, error) {
+			return "system", "convert: " + req.Input, nil
+		}),
+	)
+
+	expr, err := provider.GenerateCron(context.Background(), "daily at 6:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expr != "30 6 * * *" {
+		t.Errorf("GenerateCron = %q", expr)
+	}
+
+	if header.Get("Authorization") != "" {
+		t.Errorf("unexpected Authorization header without API key")
+	}
+	if body["model"] != "llama-test" || body["stream"] != false {
+		t.Errorf("unexpected request %v", body)
+	}
+	if options := body["options"].(map[string]any); options["temperature"] != 0.0 {
+		t.Errorf("unexpected options %v", options)
+	}
+	user := body["messages"].([]any)[1].(map[string]any)
+	if user["content"] != "convert: daily at 6:30" {
+		t.Errorf("unexpected user message %v", user)
+	}
+
+	resp, err := provider.GenerateSchedule(context.Background(), ScheduleRequest{Input: "daily at 6:30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Usage != (Usage{PromptTokens: 52, CompletionTokens: 8, TotalTokens: 60}) {
+		t.Errorf("unexpected usage %+v", resp.Usage)
+	}
+}
+
+func TestHTTPProviderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		response string
+		message  string
+	}{
+		{"openai error object", "/chat/completions", http.StatusUnauthorized, `{"error": {"message": "invalid api key"}}`, "invalid api key"},
+		{"ollama error string", "/api/chat", http.StatusNotFound, `{"error": "model not found"}`, "model not found"},
+		{"plain text", "/chat/completions", http.StatusBadGateway, "upstream down\n", "upstream down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := stubServer(t, tt.path, tt.status, tt.response, nil, nil)
+
+			var provider ScheduleProvider = NewOpenAIProvider(WithBaseURL(server.URL))
+			if tt.path == "/api/chat" {
+				provider = NewOllamaProvider(WithBaseURL(server.URL))
+			}
+
+			_, err := provider.GenerateSchedule(context.Background(), ScheduleRequest{Input: "hourly"})
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("expected HTTPError, got %v", err)
+			}
+			if httpErr.StatusCode != tt.status || httpErr.Message != tt.message {
+				t.Errorf("unexpected error %+v", httpErr)
+			}
+		})
+	}
+
+	t.Run("response too large", func(t *testing.T) {
+		answer := `{"choices": [{"message": {"content": "0 9 * * *"}}]}`
+		server := stubServer(t, "/chat/completions", http.StatusOK, answer, nil, nil)
+
+		size := int64(len(answer))
+		if _, err := NewOpenAIProvider(WithBaseURL(server.URL), WithMaxResponseSize(size)).GenerateSchedule(context.Background(), ScheduleRequest{Input: "hourly"}); err != nil {
+			t.Fatalf("response of exactly the limit rejected: %v", err)
+		}
+		_, err := NewOpenAIProvider(WithBaseURL(server.URL), WithMaxResponseSize(size-1)).GenerateSchedule(context.Background(), ScheduleRequest{Input: "hourly"})
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Fatalf("error = %v, want %v", err, ErrResponseTooLarge)
+		}
+	})
+
+	t.Run("no choices", func(t *testing.T) {
+		server := stubServer(t, "/chat/completions", http.StatusOK, `{"choices": []}`, nil, nil)
+		_, err := NewOpenAIProvider(WithBaseURL(server.URL)).GenerateSchedule(context.Background(), ScheduleRequest{Input: "hourly"})
+		if err == nil {
+			t.Fatal("expected error for empty choices")
+		}
+	})
+}
+
+func TestHTTPProviderWithMapper(t *testing.T) {
+	server := stubServer(t, "/chat/completions", http.StatusOK, `{
+		"choices": [{"message": {"content": "Sure! The expression is `+"`*/10 * * * *`"+`."}}]
+	}`, nil, nil)
+
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(NewOpenAIProvider(WithBaseURL(server.URL))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mapper.ToCronResult(context.Background(), "every ten minutes or so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expression != "*/10 * * * *" || result.Source != SourceAI || result.AI.Model != DefaultOpenAIModel {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
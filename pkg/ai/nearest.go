@@ -0,0 +1,451 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+// ErrNoNeighbour is returned by NearestProvider when no known phrasing is close enough
+var ErrNoNeighbour = errors.New("no similar known phrasing")
+
+// NearestModel is the model name NearestProvider reports
+const NearestModel = "nearest-neighbour"
+
+// NearestOption represents a functional option for configuring NearestProvider
+type NearestOption func(*NearestProvider)
+
+// WithMinSimilarity sets the cosine similarity a known phrasing needs to be
+// used, between 0 and 1, 0.6 by default
+func WithMinSimilarity(similarity float64) NearestOption {
+	return func(p *NearestProvider) {
+		p.minSimilarity = similarity
+	}
+}
+
+// WithCorpus adds recorded conversions to the phrasings the provider knows
+func WithCorpus(records ...Record) NearestOption {
+	return func(p *NearestProvider) {
+		p.corpus = append(p.corpus, records...)
+	}
+}
+
+// WithNearestExamples tries a NearestProvider before the mapper's providers,
+// so the AI is only paid for inputs unlike any known phrasing. It runs before
+// the budget and the provider wrappers, its answers are reported as
+// SourceNearest and are not recorded. The provider passed to the mapper may be
+// nil to use the examples alone.
+func WithNearestExamples(options ...NearestOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.nearest = NewNearestProvider(m.coreMapper, options...)
+	}
+}
+
+// NearestProvider is an offline ScheduleProvider that converts inputs like
+// known phrasings. It indexes the examples of the rule files and recorded
+// conversions by character trigrams weighted with TF-IDF, with numbers and
+// dictionary words such as weekday names replaced by slots. For an input it
+// finds the closest phrasing with all of the input's words, fills the input's
+// numbers and dictionary words into it, and converts the rewrite with the
+// rules. NearestProvider is deterministic and safe for concurrent use. It also
+// implements Recorder, so it can learn from the AI answers of a mapper.
+type NearestProvider struct {
+	cs            *core.CronScribe
+	minSimilarity float64
+	corpus        []Record
+
+	mu        sync.RWMutex
+	documents []nearestDocument
+	frequency map[string]int
+}
+
+// nearestDocument is an indexed phrasing
+type nearestDocument struct {
+	input    string
+	language string
+	// cron is the known answer, used when the rewrite is the phrasing itself
+	cron   string
+	tokens []nearestToken
+	vector map[string]float64
+	grams  map[string]int
+}
+
+// nearestToken is a word, a number or a punctuation mark of a phrasing
+type nearestToken struct {
+	text  string
+	start int
+	end   int
+	// slot is "#" for numbers, "#th" for ordinals such as 2nd or 15e, the
+	// dictionary name for dictionary words, and empty for other tokens
+	slot string
+}
+
+// NewNearestProvider indexes the examples of the rule sets loaded in cs
+func NewNearestProvider(cs *core.CronScribe, options ...NearestOption) *NearestProvider {
+	p := &NearestProvider{cs: cs, minSimilarity: 0.6}
+
+	// Apply all options
+	for _, option := range options {
+		option(p)
+	}
+
+	p.Rebuild()
+	return p
+}
+
+// Rebuild indexes the examples of the rule sets again, after rules were
+// reloaded, keeping the recorded conversions
+func (p *NearestProvider) Rebuild() {
+	languages := p.cs.GetSupportedLanguages()
+	sort.Strings(languages)
+
+	var documents []nearestDocument
+	for _, language := range languages {
+		set := p.cs.RuleSet(language)
+		if set == nil {
+			continue
+		}
+		for _, rule := range set.Rules {
+			for _, example := range rule.Examples {
+				if !example.Error {
+					documents = append(documents, p.document(example.Input, language, example.Cron))
+				}
+			}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range p.corpus {
+		documents = append(documents, p.document(record.Input, record.Language, record.Expression))
+	}
+	p.documents = documents
+	p.reindex()
+}
+
+// Add indexes recorded conversions
+func (p *NearestProvider) Add(records ...Record) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range records {
+		p.corpus = append(p.corpus, record)
+		p.documents = append(p.documents, p.document(record.Input, record.Language, record.Expression))
+	}
+	p.reindex()
+}
+
+// Record implements the Recorder interface by indexing the record
+func (p *NearestProvider) Record(_ context.Context, record Record) error {
+	p.Add(record)
+	return nil
+}
+
+// Len returns the number of indexed phrasings
+func (p *NearestProvider) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.documents)
+}
+
+// reindex recomputes the document frequencies and the weighted vectors
+func (p *NearestProvider) reindex() {
+	p.frequency = make(map[string]int)
+	for _, d := range p.documents {
+		for gram := range d.grams {
+			p.frequency[gram]++
+		}
+	}
+	for i := range p.documents {
+		p.documents[i].vector = p.weigh(p.documents[i].grams)
+	}
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (p *NearestProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	query := p.document(req.Input, req.Language, "")
+	vector := p.weigh(query.grams)
+
+	type candidate struct {
+		doc        *nearestDocument
+		similarity float64
+	}
+	var candidates []candidate
+	for i := range p.documents {
+		doc := &p.documents[i]
+		if req.Language != "" && doc.language != "" && doc.language != req.Language {
+			continue
+		}
+		if similarity := cosine(vector, doc.vector); similarity >= p.minSimilarity {
+			candidates = append(candidates, candidate{doc, similarity})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+
+	// The closest phrasing whose slots the input can fill wins
+	for _, c := range candidates {
+		rewrite, ok := fillSlots(c.doc, query.tokens)
+		if !ok {
+			continue
+		}
+
+		expression, err := p.convert(c.doc, rewrite, query.input)
+		if err != nil {
+			continue
+		}
+
+		return &ScheduleResponse{
+			Expression:  expression,
+			Explanation: fmt.Sprintf("converted %q like the known phrasing %q (similarity %.2f)", rewrite, c.doc.input, c.similarity),
+			Confidence:  math.Round(c.similarity*100) / 100,
+			Model:       NearestModel,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w for %q", ErrNoNeighbour, req.Input)
+}
+
+// GenerateCron implements the AIProvider interface
+func (p *NearestProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, p, input)
+}
+
+// convert runs the rules of the phrasing's language on a rewrite. The answer
+// of a phrasing without a matching rule, such as a recorded AI answer, is
+// only reused for the same words.
+func (p *NearestProvider) convert(doc *nearestDocument, rewrite, input string) (string, error) {
+	if set := p.cs.RuleSet(doc.language); set != nil {
+		if expression, _, err := set.Convert(rewrite); err == nil {
+			if _, err := cron.Parse(expression); err == nil {
+				return expression, nil
+			}
+		}
+	}
+
+	if doc.cron != "" && sameWords(input, doc.input) {
+		return doc.cron, nil
+	}
+	return "", ErrNoNeighbour
+}
+
+// sameWords reports whether two phrasings have the same words and numbers,
+// ignoring punctuation
+func sameWords(a, b string) bool {
+	return strings.Join(nearestLetters.FindAllString(a, -1), " ") == strings.Join(nearestLetters.FindAllString(b, -1), " ")
+}
+
+var (
+	nearestWord    = regexp.MustCompile(`\p{L}+|\d+|[^\s\p{L}\d]`)
+	nearestLetters = regexp.MustCompile(`\p{L}+|\d+`)
+)
+
+// document tokenizes and slots a phrasing
+func (p *NearestProvider) document(input, language, cronExpr string) nearestDocument {
+	text := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	doc := nearestDocument{input: text, language: language, cron: cronExpr, grams: make(map[string]int)}
+
+	words := p.dictionaryWords(language)
+	var normalized []string
+	locs := nearestWord.FindAllStringIndex(text, -1)
+	for i := 0; i < len(locs); i++ {
+		loc := locs[i]
+		t := nearestToken{text: text[loc[0]:loc[1]], start: loc[0], end: loc[1]}
+		switch {
+		case unicode.IsDigit(rune(t.text[0])):
+			t.slot = "#"
+			// A suffix joined to the number makes it an ordinal, unless it is
+			// a dictionary word such as the "pm" of "5pm"
+			if i+1 < len(locs) && locs[i+1][0] == loc[1] {
+				suffix := text[locs[i+1][0]:locs[i+1][1]]
+				if unicode.IsLetter([]rune(suffix)[0]) && words[suffix] == "" {
+					t.text, t.end, t.slot = t.text+suffix, locs[i+1][1], "#th"
+					i++
+				}
+			}
+		default:
+			t.slot = words[t.text]
+		}
+		doc.tokens = append(doc.tokens, t)
+
+		if t.slot != "" {
+			normalized = append(normalized, "<"+t.slot+">")
+		} else {
+			normalized = append(normalized, t.text)
+		}
+	}
+
+	// Character trigrams of every word, with word boundaries
+	for _, word := range normalized {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			doc.grams[string(runes[i:i+3])]++
+		}
+	}
+	return doc
+}
+
+// dictionaryWords maps the dictionary words of a language, or of all
+// languages when it is unknown, to the name of their dictionary
+func (p *NearestProvider) dictionaryWords(language string) map[string]string {
+	languages := []string{language}
+	if language == "" {
+		languages = p.cs.GetSupportedLanguages()
+		sort.Strings(languages)
+	}
+
+	words := make(map[string]string)
+	for _, lang := range languages {
+		set := p.cs.RuleSet(lang)
+		if set == nil {
+			continue
+		}
+		names := make([]string, 0, len(set.Dictionaries))
+		for name := range set.Dictionaries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for word := range set.Dictionaries[name] {
+				if _, ok := words[word]; !ok {
+					words[word] = name
+				}
+			}
+		}
+	}
+	return words
+}
+
+// weigh turns trigram counts into a unit vector weighted by TF-IDF
+func (p *NearestProvider) weigh(grams map[string]int) map[string]float64 {
+	n := float64(len(p.documents))
+	vector := make(map[string]float64, len(grams))
+	var norm float64
+	for gram, count := range grams {
+		idf := math.Log((1+n)/(1+float64(p.frequency[gram]))) + 1
+		w := float64(count) * idf
+		vector[gram] = w
+		norm += w * w
+	}
+
+	norm = math.Sqrt(norm)
+	for gram := range vector {
+		vector[gram] /= norm
+	}
+	return vector
+}
+
+func cosine(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot float64
+	for gram, w := range a {
+		dot += w * b[gram]
+	}
+	return dot
+}
+
+// fillSlots rewrites a phrasing with the numbers and dictionary words of the
+// input, slot by slot in order. Ordinals only fill ordinal slots. Slots of the
+// phrasing the input has no value for are left out, such as the "pm" of
+// "at 3:45pm" for "at 10:15". It fails when the input has values the phrasing
+// has no slot for, or words the phrasing lacks, such as the "other" of
+// "every other friday", which the rewrite would drop.
+func fillSlots(doc *nearestDocument, input []nearestToken) (string, bool) {
+	values := make(map[string][]string)
+	for _, t := range input {
+		switch {
+		case t.slot != "":
+			values[t.slot] = append(values[t.slot], t.text)
+		case unicode.IsLetter([]rune(t.text)[0]) && !hasWord(doc, t.text):
+			return "", false
+		}
+	}
+
+	var b strings.Builder
+	last := 0
+	used := make(map[string]int)
+	for _, t := range doc.tokens {
+		if t.slot == "" {
+			continue
+		}
+		n := used[t.slot]
+		used[t.slot]++
+		if n >= len(values[t.slot]) {
+			// Punctuation joined to the slot goes with it, as the ":" of "3:45"
+			b.WriteString(strings.TrimRightFunc(doc.input[last:t.start], func(r rune) bool {
+				return unicode.IsSpace(r) || unicode.IsPunct(r)
+			}))
+			last = t.end
+			continue
+		}
+
+		b.WriteString(doc.input[last:t.start])
+		b.WriteString(values[t.slot][n])
+		last = t.end
+	}
+	b.WriteString(doc.input[last:])
+
+	for slot, v := range values {
+		if used[slot] < len(v) {
+			return "", false
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " "), true
+}
+
+// hasWord reports whether the phrasing has a word, allowing for a typo: one
+// edit in words of up to five letters, two in longer words
+func hasWord(doc *nearestDocument, word string) bool {
+	allowed := 1
+	if utf8.RuneCountInString(word) > 5 {
+		allowed = 2
+	}
+	for _, t := range doc.tokens {
+		if t.slot == "" && editDistance(word, t.text) <= allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between two words
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	row := make([]int, len(y)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			diagonal, row[j] = row[j], min(row[j]+1, row[j-1]+1, diagonal+cost)
+		}
+	}
+	return row[len(y)]
+}
@@ -0,0 +1,158 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+)
+
+func TestNearestProvider(t *testing.T) {
+	cs, err := core.New("../core/rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := NewNearestProvider(cs)
+
+	tests := []struct {
+		input    string
+		language string
+		want     string
+	}{
+		{"evry 7 minutes", "", "*/7 * * * *"},
+		{"every 7 minute", "en", "*/7 * * * *"},
+		// The weekday and time are filled into "every tuesday at 3:45pm"
+		{"every thursday at 10:15", "en", "15 10 * * 4"},
+		{"elke donderdag om 8:15", "", "15 8 * * 4"},
+		// Ordinals fill ordinal slots only
+		{"each 3rd of month at 4:15pm", "en", "15 16 3 * *"},
+		{"каждые 20 минут", "ru", "*/20 * * * *"},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		response, err := provider.GenerateSchedule(ctx, ScheduleRequest{Input: tt.input, Language: tt.language})
+		if err != nil {
+			t.Errorf("GenerateSchedule(%q) error = %v", tt.input, err)
+			continue
+		}
+		if response.Expression != tt.want || response.Model != NearestModel || response.Confidence < 0.6 {
+			t.Errorf("GenerateSchedule(%q) = %+v, want %s", tt.input, response, tt.want)
+		}
+	}
+
+	for _, req := range []ScheduleRequest{
+		{Input: "fortnightly on fridays"},
+		{Input: "каждые 20 минут", Language: "en"},
+		{Input: "every 5 minutes on mondays at 9", Language: "en"},
+		// An ordinal does not fill the hour and minute slots of "every tuesday at 3:45pm"
+		{Input: "every 2nd friday at 5pm", Language: "en"},
+		// Rewriting as "every friday at 5pm" would drop "other"
+		{Input: "at 5pm every other friday", Language: "en"},
+		{Input: "every day except mondays", Language: "en"},
+	} {
+		if _, err := provider.GenerateSchedule(ctx, req); !errors.Is(err, ErrNoNeighbour) {
+			t.Errorf("GenerateSchedule(%+v) error = %v, want %v", req, err, ErrNoNeighbour)
+		}
+	}
+}
+
+func TestNearestProviderCorpus(t *testing.T) {
+	cs, err := core.New("../core/rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := NewNearestProvider(cs, WithCorpus(Record{Input: "Business mornings", Language: "en", Expression: "0 9 * * 1-5"}))
+	examples := provider.Len()
+
+	ctx := context.Background()
+	if got, err := provider.GenerateCron(ctx, "business mornings!"); err != nil || got != "0 9 * * 1-5" {
+		t.Errorf("GenerateCron() = %q, %v", got, err)
+	}
+	// Recorded answers without a rule are not transferred to other words
+	if _, err := provider.GenerateCron(ctx, "business evenings"); !errors.Is(err, ErrNoNeighbour) {
+		t.Errorf("error = %v, want %v", err, ErrNoNeighbour)
+	}
+
+	// Recording indexes new answers, and rebuilding keeps them
+	if err := provider.Record(ctx, Record{Input: "business evenings", Language: "en", Expression: "0 18 * * 1-5"}); err != nil {
+		t.Fatal(err)
+	}
+	provider.Rebuild()
+	if got, err := provider.GenerateCron(ctx, "business evenings"); err != nil || got != "0 18 * * 1-5" || provider.Len() != examples+1 {
+		t.Errorf("GenerateCron() = %q, %v with %d phrasings", got, err, provider.Len())
+	}
+}
+
+func TestNearestExamplesInMapper(t *testing.T) {
+	paid := &answersProvider{answers: []string{"0 9 * * 5/2"}}
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(paid),
+		WithNearestExamples(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mapper.ToCronResult(context.Background(), "evry 7 minutes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expression != "*/7 * * * *" || result.Source != SourceNearest || result.AI.Model != NearestModel || paid.calls != 0 {
+		t.Errorf("result = %+v, paid provider called %d times", result, paid.calls)
+	}
+
+	if _, err := mapper.ToCron("fortnightly on fridays"); err != nil || paid.calls != 1 {
+		t.Errorf("paid provider not asked: %v, %d calls", err, paid.calls)
+	}
+
+	// The examples alone are enough for a provider
+	mapper, err = NewBraveHumanCronMapper("../core/rules", nil, WithNearestExamples(WithMinSimilarity(0.7)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := mapper.ToCron("evry 7 minutes"); err != nil || got != "*/7 * * * *" {
+		t.Errorf("ToCron() = %q, %v", got, err)
+	}
+}
+
+func TestNearestExamplesBeforeBudget(t *testing.T) {
+	budget := NewBudget(WithDailyTokens(10))
+	budget.Charge("any", Usage{TotalTokens: 10})
+
+	paid := &answersProvider{answers: []string{"0 9 * * 5/2"}}
+	var recorded int
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(paid),
+		WithNearestExamples(),
+		WithBudget(budget),
+		WithCircuitBreaker(),
+		WithRecorder(RecorderFunc(func(context.Context, Record) error {
+			recorded++
+			return nil
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The exhausted budget only refuses the paid provider
+	result, err := mapper.ToCronResult(context.Background(), "evry 7 minutes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expression != "*/7 * * * *" || result.Source != SourceNearest {
+		t.Errorf("result = %s from %s", result.Expression, result.Source)
+	}
+	if _, err := mapper.ToCron("fortnightly on fridays"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("error = %v, want the budget refusal", err)
+	}
+
+	if recorded != 0 || paid.calls != 0 {
+		t.Errorf("%d answers recorded, paid provider called %d times", recorded, paid.calls)
+	}
+	if stats := mapper.Stats(); len(stats.Providers) != 1 || stats.Chain != nil {
+		t.Errorf("nearest examples wrapped as a provider: %+v", stats)
+	}
+}
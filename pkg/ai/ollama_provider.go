@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"context"
+)
+
+// DefaultOllamaBaseURL is the address of a local Ollama server
+const DefaultOllamaBaseURL = "http://localhost:11434"
+
+// DefaultOllamaModel is the model used when none is configured
+const DefaultOllamaModel = "llama3.2"
+
+// OllamaProvider calls the Ollama /api/chat API over plain net/http
+type OllamaProvider struct {
+	config *httpConfig
+}
+
+// NewOllamaProvider creates a provider for an Ollama server
+func NewOllamaProvider(options ...ProviderOption) *OllamaProvider {
+	return &OllamaProvider{config: newHTTPConfig(DefaultOllamaBaseURL, DefaultOllamaModel, options)}
+}
+
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   any           `json:"format,omitempty"`
+	Options  struct {
+		Temperature float64 `json:"temperature"`
+	} `json:"options"`
+}
+
+type ollamaResponse struct {
+	Model           string      `json:"model"`
+	Message         chatMessage `json:"message"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (p *OllamaProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	messages, err := p.config.messages(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body := ollamaRequest{
+		Model:    p.config.model,
+		Messages: messages,
+	}
+	body.Options.Temperature = p.config.temperature
+	if p.config.structured {
+		body.Format = StructuredScheduleSchema()
+	}
+
+	var resp ollamaResponse
+	if err := p.config.post(ctx, "/api/chat", body, &resp); err != nil {
+		return nil, err
+	}
+
+	model := resp.Model
+	if model == "" {
+		model = p.config.model
+	}
+
+	result := &ScheduleResponse{
+		Expression: resp.Message.Content,
+		Model:      model,
+		Usage: Usage{
+			PromptTokens:     resp.PromptEvalCount,
+			CompletionTokens: resp.EvalCount,
+			TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+		},
+	}
+	if p.config.structured {
+		if err := structuredResponse(resp.Message, req, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GenerateCron implements the AIProvider interface
+func (p *OllamaProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, p, input)
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultOpenAIBaseURL is the base URL of the OpenAI API
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// DefaultOpenAIModel is the model used when none is configured
+const DefaultOpenAIModel = "gpt-4o-mini"
+
+// OpenAIProvider calls an OpenAI-compatible chat completions API over plain net/http.
+// It works with OpenAI and with any server exposing /chat/completions, such as
+// vLLM, LM Studio or llama.cpp.
+type OpenAIProvider struct {
+	config *httpConfig
+}
+
+// NewOpenAIProvider creates a provider for an OpenAI-compatible API
+func NewOpenAIProvider(options ...ProviderOption) *OpenAIProvider {
+	return &OpenAIProvider{config: newHTTPConfig(DefaultOpenAIBaseURL, DefaultOpenAIModel, options)}
+}
+
+type openAIRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	Tools       []chatTool    `json:"tools,omitempty"`
+	ToolChoice  any           `json:"tool_choice,omitempty"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (p *OpenAIProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	messages, err := p.config.messages(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body := openAIRequest{
+		Model:       p.config.model,
+		Messages:    messages,
+		Temperature: p.config.temperature,
+	}
+	if p.config.structured {
+		body.Tools = []chatTool{structuredTool()}
+		body.ToolChoice = map[string]any{"type": "function", "function": map[string]string{"name": StructuredToolName}}
+	}
+
+	var resp openAIResponse
+	if err := p.config.post(ctx, "/chat/completions", body, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in AI API response")
+	}
+
+	model := resp.Model
+	if model == "" {
+		model = p.config.model
+	}
+
+	result := &ScheduleResponse{
+		Expression: resp.Choices[0].Message.Content,
+		Model:      model,
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		},
+	}
+	if p.config.structured {
+		if err := structuredResponse(resp.Choices[0].Message, req, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// GenerateCron implements the AIProvider interface
+func (p *OpenAIProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, p, input)
+}
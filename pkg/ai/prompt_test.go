@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+func TestPromptsRender(t *testing.T) {
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	req := ScheduleRequest{
+		Input:    "elke werkdag om half negen",
+		Language: "nl",
+		Dialect:  cron.Standard,
+		Location: amsterdam,
+		Examples: []Example{
+			{Input: "elk uur", Cron: "0 * * * *"},
+			{Input: "elke maandag om 9 uur", Cron: "0 9 * * 1"},
+			{Input: "elke dag om 10:30", Cron: "30 10 * * *"},
+		},
+	}
+
+	prompts, err := NewPrompts(WithFewShot(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	system, user, err := prompts.Render(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"written in Dutch", "5-field format", "Europe/Amsterdam", "CRON_TZ=Europe/Amsterdam"} {
+		if !strings.Contains(system, want) {
+			t.Errorf("system prompt lacks %q:\n%s", want, system)
+		}
+	}
+
+	// The examples sharing words with the input come first
+	want := "Examples:\nelke maandag om 9 uur => 0 9 * * 1\nelke dag om 10:30 => 30 10 * * *\n\nConvert: \"elke werkdag om half negen\""
+	if user != want {
+		t.Errorf("user prompt:\n%s\nwant:\n%s", user, want)
+	}
+
+	system, user, err = DefaultPrompts().Render(ScheduleRequest{Input: "hourly"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(system, "written in") || strings.Contains(system, "time zone") || user != `Convert: "hourly"` {
+		t.Errorf("unexpected prompts without context:\n%s\n%s", system, user)
+	}
+}
+
+func TestPromptsDialect(t *testing.T) {
+	tests := []struct {
+		dialect cron.Dialect
+		want    string
+	}{
+		{cron.Standard, "standard dialect in the 5-field format (minute hour"},
+		{cron.StandardSeconds, "standard-seconds dialect in the 6-field format (second minute hour"},
+		{cron.Dialect{Name: "quartz", Seconds: true, Years: true}, "with a year field last"},
+	}
+	for _, tt := range tests {
+		system, _, err := DefaultPrompts().Render(ScheduleRequest{Input: "every 30 seconds", Dialect: tt.dialect})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(system, tt.want) {
+			t.Errorf("%s system prompt lacks %q:\n%s", tt.dialect.Name, tt.want, system)
+		}
+	}
+}
+
+func TestPromptsPerLanguage(t *testing.T) {
+	prompts, err := NewPrompts(
+		WithPromptTemplates("ru", "Переведи описание расписания в cron.", ""),
+		WithPromptTemplates("", "", "Input: {{.Input}} ({{.Language}})"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	system, user, err := prompts.Render(ScheduleRequest{Input: "каждый час", Language: "ru"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if system != "Переведи описание расписания в cron." || user != "Input: каждый час (ru)" {
+		t.Errorf("unexpected ru prompts %q, %q", system, user)
+	}
+
+	system, _, err = prompts.Render(ScheduleRequest{Input: "hourly", Language: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(system, "written in English") {
+		t.Errorf("default system template not used: %q", system)
+	}
+
+	if _, err := NewPrompts(WithPromptTemplates("en", "{{.Input", "")); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+	if _, _, err := func() (string, string, error) {
+		p, _ := NewPrompts(WithPromptTemplates("en", "{{.Missing}}", ""))
+		return p.Render(ScheduleRequest{Language: "en"})
+	}(); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestRequestExamples(t *testing.T) {
+	provider := &recordingProvider{response: ScheduleResponse{Expression: "0 9 * * 1"}}
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mapper.SetLanguage("ru"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mapper.ToCronResult(context.Background(), "по понедельникам утром"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapper.AutoDetectResult(context.Background(), "mondays in the morning"); err != nil {
+		t.Fatal(err)
+	}
+
+	ru, auto := provider.requests[0].Examples, provider.requests[1].Examples
+	if len(ru) == 0 || len(auto) <= len(ru) {
+		t.Fatalf("expected examples of ru and of all languages, got %d and %d", len(ru), len(auto))
+	}
+	for _, e := range ru {
+		if e.Cron == "" || !strings.ContainsAny(e.Input, "абвгдеёжзийклмнопрстуфхцчшщъыьэюя") {
+			t.Errorf("unexpected ru example %+v", e)
+		}
+	}
+}
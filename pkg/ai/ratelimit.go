@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket holds up to burst tokens and refills them at rate per second
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// refill adds the tokens earned since the last call
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+}
+
+// take spends a token, or returns how long to wait for the next one
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if b.rate <= 0 {
+		return false, 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// full reports whether the bucket refilled completely, so it can be forgotten
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+// RateLimitStats holds the counters of a RateLimitedProvider
+type RateLimitStats struct {
+	// Calls is the number of requests received
+	Calls int64 `json:"calls"`
+	// Rejected is the number of requests refused for lack of tokens
+	Rejected int64 `json:"rejected"`
+	// Tokens is the number of requests that can be made right away
+	Tokens float64 `json:"tokens"`
+}
+
+// RateLimitedProvider limits the request rate to the wrapped provider with a
+// token bucket. Requests over the limit fail right away with a *BudgetError,
+// so that a chain moves on to the next provider.
+type RateLimitedProvider struct {
+	provider ScheduleProvider
+	now      func() time.Time
+
+	mu     sync.Mutex
+	bucket *tokenBucket
+	stats  RateLimitStats
+}
+
+// NewRateLimitedProvider allows rate requests per second to a provider, with
+// bursts of up to burst requests
+func NewRateLimitedProvider(provider ScheduleProvider, rate float64, burst int) *RateLimitedProvider {
+	return &RateLimitedProvider{
+		provider: provider,
+		now:      time.Now,
+		bucket:   newTokenBucket(rate, max(burst, 1), time.Now()),
+	}
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (p *RateLimitedProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	p.mu.Lock()
+	p.stats.Calls++
+	ok, wait := p.bucket.take(p.now())
+	if !ok {
+		p.stats.Rejected++
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return nil, &BudgetError{Reason: BudgetProviderRate, RetryAfter: wait}
+	}
+	return p.provider.GenerateSchedule(ctx, req)
+}
+
+// GenerateCron implements the AIProvider interface
+func (p *RateLimitedProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, p, input)
+}
+
+// Stats returns a snapshot of the rate limit counters
+func (p *RateLimitedProvider) Stats() RateLimitStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	p.bucket.refill(p.now())
+	stats.Tokens = p.bucket.tokens
+	return stats
+}
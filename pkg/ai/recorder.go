@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Record is a conversion the AI answered because the rules could not. Records
+// are the input of the rule mining command.
+type Record struct {
+	Time     time.Time `json:"time"`
+	Input    string    `json:"input"`
+	Language string    `json:"language,omitempty"`
+	// Expression is the validated cron expression the AI answered
+	Expression string `json:"expression"`
+	Model      string `json:"model,omitempty"`
+}
+
+// Recorder captures AI fallbacks
+type Recorder interface {
+	Record(ctx context.Context, record Record) error
+}
+
+// RecorderFunc adapts a function to the Recorder interface
+type RecorderFunc func(ctx context.Context, record Record) error
+
+// Record implements the Recorder interface
+func (f RecorderFunc) Record(ctx context.Context, record Record) error {
+	return f(ctx, record)
+}
+
+// WithRecorder records every conversion that fell back to the AI. Recording
+// is best effort, recorder errors do not fail the conversion.
+func WithRecorder(recorder Recorder) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		m.recorder = recorder
+	}
+}
+
+// JSONLRecorder writes records as JSON lines, the format read by ReadRecords
+type JSONLRecorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLRecorder creates a recorder writing to w, typically a file opened for appending
+func NewJSONLRecorder(w io.Writer) *JSONLRecorder {
+	return &JSONLRecorder{w: w}
+}
+
+// Record implements the Recorder interface
+func (r *JSONLRecorder) Record(_ context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadRecords reads records written by a JSONLRecorder, skipping blank lines
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	provider := &flakyProvider{expression: "0 7 * * 6"}
+
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(provider),
+		WithRecorder(NewJSONLRecorder(&buf)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mapper.ToCron("saturday mornings at seven"); err != nil {
+		t.Fatal(err)
+	}
+	// Rules conversions are not recorded
+	if _, err := mapper.ToCron("every day at 9:30"); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadRecords(strings.NewReader(buf.String() + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %+v", records)
+	}
+	r := records[0]
+	if r.Input != "saturday mornings at seven" || r.Language != "en" || r.Expression != "0 7 * * 6" || r.Time.IsZero() {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// AI-first answers are not fallbacks
+	var recorded int
+	mapper, err = NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(provider),
+		WithAIFirst(true),
+		WithRecorder(RecorderFunc(func(context.Context, Record) error {
+			recorded++
+			return nil
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapper.ToCron("saturday mornings at seven"); err != nil {
+		t.Fatal(err)
+	}
+	if recorded != 0 {
+		t.Errorf("AI-first answer recorded %d times", recorded)
+	}
+
+	if _, err := ReadRecords(strings.NewReader("{not json}\n")); err == nil {
+		t.Error("expected an error for an invalid record")
+	}
+}
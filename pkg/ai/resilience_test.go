@@ -0,0 +1,249 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyProvider fails with the scripted errors, then answers with its expression
+type flakyProvider struct {
+	mu         sync.Mutex
+	errs       []error
+	calls      int
+	expression string
+}
+
+func (p *flakyProvider) GenerateSchedule(_ context.Context, _ ScheduleRequest) (*ScheduleResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &ScheduleResponse{Expression: p.expression}, nil
+}
+
+var (
+	errUnavailable = &HTTPError{StatusCode: http.StatusServiceUnavailable, Message: "overloaded"}
+	errBadRequest  = &HTTPError{StatusCode: http.StatusBadRequest, Message: "bad request"}
+)
+
+func TestChainProvider(t *testing.T) {
+	first := &flakyProvider{errs: []error{errBadRequest}, expression: "1 * * * *"}
+	second := &flakyProvider{expression: "2 * * * *"}
+	chain := NewChainProvider(first, second)
+
+	resp, err := chain.GenerateSchedule(context.Background(), ScheduleRequest{Input: "x"})
+	if err != nil || resp.Expression != "2 * * * *" {
+		t.Fatalf("got %v, %v", resp, err)
+	}
+	if expr, err := chain.GenerateCron(context.Background(), "x"); err != nil || expr != "1 * * * *" {
+		t.Fatalf("got %q, %v", expr, err)
+	}
+
+	stats := chain.Stats()
+	if stats.Calls != 2 || stats.Fallbacks != 1 || stats.Failures != 0 || stats.Served[0] != 1 || stats.Served[1] != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	failing := NewChainProvider(&flakyProvider{errs: []error{errBadRequest}}, &flakyProvider{errs: []error{errUnavailable}})
+	_, err = failing.GenerateSchedule(context.Background(), ScheduleRequest{})
+	if !errors.Is(err, errBadRequest) || !errors.Is(err, errUnavailable) {
+		t.Errorf("expected both errors, got %v", err)
+	}
+	if failing.Stats().Failures != 1 {
+		t.Errorf("unexpected stats %+v", failing.Stats())
+	}
+}
+
+func TestRetryProvider(t *testing.T) {
+	provider := &flakyProvider{errs: []error{errUnavailable, context.DeadlineExceeded}, expression: "0 * * * *"}
+	retry := NewRetryProvider(provider, WithMaxAttempts(4), WithBackoff(100*time.Millisecond, 150*time.Millisecond), WithJitter(0.5))
+
+	var delays []time.Duration
+	retry.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	retry.random = func() float64 { return 1 }
+
+	resp, err := retry.GenerateSchedule(context.Background(), ScheduleRequest{})
+	if err != nil || resp.Expression != "0 * * * *" {
+		t.Fatalf("got %v, %v", resp, err)
+	}
+
+	// 100ms and 200ms capped to 150ms, both reduced by the full jitter of 50%
+	if len(delays) != 2 || delays[0] != 50*time.Millisecond || delays[1] != 75*time.Millisecond {
+		t.Errorf("unexpected delays %v", delays)
+	}
+	if stats := retry.Stats(); stats != (RetryStats{Calls: 1, Attempts: 3, Retries: 2}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	t.Run("permanent error", func(t *testing.T) {
+		provider := &flakyProvider{errs: []error{errBadRequest}}
+		retry := NewRetryProvider(provider)
+		if _, err := retry.GenerateSchedule(context.Background(), ScheduleRequest{}); !errors.Is(err, errBadRequest) {
+			t.Fatalf("expected bad request, got %v", err)
+		}
+		if provider.calls != 1 || retry.Stats().Failures != 1 {
+			t.Errorf("permanent error retried: %d calls", provider.calls)
+		}
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		provider := &flakyProvider{errs: []error{errUnavailable, errUnavailable, errUnavailable}}
+		retry := NewRetryProvider(provider, WithBackoff(time.Millisecond, time.Millisecond))
+		if _, err := retry.GenerateSchedule(context.Background(), ScheduleRequest{}); !errors.Is(err, errUnavailable) {
+			t.Fatalf("expected unavailable, got %v", err)
+		}
+		if provider.calls != 3 {
+			t.Errorf("expected 3 attempts, got %d", provider.calls)
+		}
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		retry := NewRetryProvider(&flakyProvider{errs: []error{errUnavailable}}, WithBackoff(time.Hour, time.Hour))
+		_, err := retry.GenerateSchedule(ctx, ScheduleRequest{})
+		if !errors.Is(err, errUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected provider and context errors, got %v", err)
+		}
+	})
+}
+
+// slowProvider answers after its delay unless the context is done first
+type slowProvider struct {
+	delay time.Duration
+}
+
+func (p *slowProvider) GenerateSchedule(ctx context.Context, _ ScheduleRequest) (*ScheduleResponse, error) {
+	select {
+	case <-time.After(p.delay):
+		return &ScheduleResponse{Expression: "* * * * *"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestTimeoutProvider(t *testing.T) {
+	timeout := NewTimeoutProvider(&slowProvider{delay: time.Second}, 10*time.Millisecond)
+
+	_, err := timeout.GenerateSchedule(context.Background(), ScheduleRequest{})
+	if !errors.Is(err, context.DeadlineExceeded) || !IsRetryable(err) {
+		t.Fatalf("expected retryable deadline error, got %v", err)
+	}
+
+	fast := NewTimeoutProvider(&slowProvider{}, time.Second)
+	if _, err := fast.GenerateSchedule(context.Background(), ScheduleRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := timeout.Stats(); stats != (TimeoutStats{Calls: 1, Timeouts: 1}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := &flakyProvider{errs: []error{errUnavailable, errUnavailable, errUnavailable}, expression: "0 0 * * *"}
+	breaker := NewCircuitBreaker(provider, WithFailureThreshold(2), WithCooldown(time.Minute))
+	breaker.now = func() time.Time { return now }
+
+	call := func() error {
+		_, err := breaker.GenerateSchedule(context.Background(), ScheduleRequest{})
+		return err
+	}
+
+	call()
+	call()
+	if state := breaker.Stats().State; state != BreakerOpen {
+		t.Fatalf("expected open breaker, got %s", state)
+	}
+	if err := call(); !errors.Is(err, ErrCircuitOpen) || IsRetryable(err) {
+		t.Fatalf("expected non-retryable ErrCircuitOpen, got %v", err)
+	}
+	if provider.calls != 2 {
+		t.Errorf("open breaker reached the provider: %d calls", provider.calls)
+	}
+
+	// The trial after the cool-down fails and opens the breaker again
+	now = now.Add(time.Minute)
+	if state := breaker.Stats().State; state != BreakerHalfOpen {
+		t.Fatalf("expected half-open breaker, got %s", state)
+	}
+	if err := call(); !errors.Is(err, errUnavailable) {
+		t.Fatalf("expected trial failure, got %v", err)
+	}
+	if err := call(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open breaker after failed trial, got %v", err)
+	}
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := breaker.Stats()
+	want := BreakerStats{State: BreakerClosed, Calls: 6, Rejected: 2, Failures: 3, Trips: 2}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestBraveMapperResilience(t *testing.T) {
+	primary := &flakyProvider{errs: []error{errUnavailable, errUnavailable}}
+	fallback := &flakyProvider{expression: "15 10 * * *"}
+
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(primary),
+		WithFallbackProviders(fallback),
+		WithRetry(WithMaxAttempts(2), WithBackoff(time.Millisecond, time.Millisecond)),
+		WithAttemptTimeout(time.Second),
+		WithCircuitBreaker(WithFailureThreshold(1)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mapper.ToCronResult(context.Background(), "sometime mid morning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expression != "15 10 * * *" {
+		t.Errorf("unexpected expression %q", result.Expression)
+	}
+
+	stats := mapper.Stats()
+	if stats.Chain == nil || stats.Chain.Fallbacks != 1 || len(stats.Providers) != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if retry := stats.Providers[0].Retry; retry == nil || retry.Attempts != 2 {
+		t.Errorf("unexpected retry stats %+v", retry)
+	}
+	if breaker := stats.Providers[0].Breaker; breaker == nil || breaker.State != BreakerOpen {
+		t.Errorf("unexpected breaker stats %+v", breaker)
+	}
+	if timeout := stats.Providers[1].Timeout; timeout == nil || timeout.Calls != 1 {
+		t.Errorf("unexpected timeout stats %+v", timeout)
+	}
+
+	plain, err := NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(fallback))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := plain.Stats(); stats.Chain != nil || stats.Providers[0] != (ProviderStats{}) {
+		t.Errorf("unexpected stats without wrappers %+v", stats)
+	}
+}
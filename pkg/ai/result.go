@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/schedule"
+)
+
+// Source tells which engine produced a conversion
+type Source string
+
+const (
+	// SourceRules marks expressions produced by the YAML rules
+	SourceRules Source = "rules"
+	// SourceAI marks expressions produced by the AI provider
+	SourceAI Source = "ai"
+	// SourceNearest marks expressions converted like a known phrasing, see WithNearestExamples
+	SourceNearest Source = "nearest"
+)
+
+// Result is the detailed outcome of a conversion in brave mode
+type Result struct {
+	// Expression is the validated cron expression
+	Expression string `json:"expression"`
+	Source     Source `json:"source"`
+	// Rules is the rules engine result, set when the rules produced the expression
+	Rules *core.Result `json:"rules,omitempty"`
+	// AI is the provider response, set when the AI or the nearest known
+	// phrasing produced the expression
+	AI *ScheduleResponse `json:"ai,omitempty"`
+	// Verification reports how the AI answer was verified, set with WithVerification
+	Verification *Verification `json:"verification,omitempty"`
+	// Routing explains the route the conversion took, set with WithRouting
+	Routing *RoutingDecision `json:"routing,omitempty"`
+}
+
+// Schedule returns the typed fields of the expression, nil when it cannot be parsed
+func (r *Result) Schedule() *schedule.Schedule {
+	if r.Rules != nil {
+		return r.Rules.Schedule()
+	}
+	s, err := schedule.Parse(r.Expression)
+	if err != nil {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// RetryStats holds the counters of a RetryProvider
+type RetryStats struct {
+	// Calls is the number of requests received
+	Calls int64 `json:"calls"`
+	// Attempts is the number of requests sent to the wrapped provider
+	Attempts int64 `json:"attempts"`
+	// Retries is the number of attempts after the first one
+	Retries int64 `json:"retries"`
+	// Failures is the number of requests that failed after all attempts
+	Failures int64 `json:"failures"`
+}
+
+// RetryOption represents a functional option for configuring RetryProvider
+type RetryOption func(*RetryProvider)
+
+// WithMaxAttempts sets how many times a request is sent, 3 by default
+func WithMaxAttempts(attempts int) RetryOption {
+	return func(r *RetryProvider) {
+		if attempts > 0 {
+			r.maxAttempts = attempts
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry and the maximum delay.
+// The delay doubles after every attempt. The defaults are 200ms and 5s.
+func WithBackoff(base, max time.Duration) RetryOption {
+	return func(r *RetryProvider) {
+		r.baseDelay = base
+		r.maxDelay = max
+	}
+}
+
+// WithJitter sets the fraction of every delay that is randomized, 0.5 by default
+func WithJitter(fraction float64) RetryOption {
+	return func(r *RetryProvider) {
+		r.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// WithRetryIf replaces IsRetryable as the test for retryable errors
+func WithRetryIf(retryable func(error) bool) RetryOption {
+	return func(r *RetryProvider) {
+		r.retryable = retryable
+	}
+}
+
+// RetryProvider sends a request again when the wrapped provider fails with a
+// retryable error, waiting with exponential backoff and jitter between attempts
+type RetryProvider struct {
+	provider    ScheduleProvider
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	jitter      float64
+	retryable   func(error) bool
+	random      func() float64
+	sleep       func(ctx context.Context, d time.Duration) error
+
+	mu    sync.Mutex
+	stats RetryStats
+}
+
+// NewRetryProvider wraps a provider with retries
+func NewRetryProvider(provider ScheduleProvider, options ...RetryOption) *RetryProvider {
+	r := &RetryProvider{
+		provider:    provider,
+		maxAttempts: 3,
+		baseDelay:   200 * time.Millisecond,
+		maxDelay:    5 * time.Second,
+		jitter:      0.5,
+		retryable:   IsRetryable,
+		random:      rand.Float64,
+		sleep:       sleep,
+	}
+
+	// Apply all options
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (r *RetryProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	r.count(func(s *RetryStats) { s.Calls++ })
+
+	for attempt := 1; ; attempt++ {
+		r.count(func(s *RetryStats) {
+			s.Attempts++
+			if attempt > 1 {
+				s.Retries++
+			}
+		})
+
+		resp, err := r.provider.GenerateSchedule(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		if attempt >= r.maxAttempts || ctx.Err() != nil || !r.retryable(err) {
+			r.count(func(s *RetryStats) { s.Failures++ })
+			return nil, err
+		}
+
+		if sleepErr := r.sleep(ctx, r.delay(attempt)); sleepErr != nil {
+			r.count(func(s *RetryStats) { s.Failures++ })
+			return nil, errors.Join(err, sleepErr)
+		}
+	}
+}
+
+// GenerateCron implements the AIProvider interface
+func (r *RetryProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, r, input)
+}
+
+// Stats returns a snapshot of the retry counters
+func (r *RetryProvider) Stats() RetryStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+func (r *RetryProvider) count(update func(*RetryStats)) {
+	r.mu.Lock()
+	update(&r.stats)
+	r.mu.Unlock()
+}
+
+// delay returns the randomized wait after the given attempt
+func (r *RetryProvider) delay(attempt int) time.Duration {
+	d := r.baseDelay
+	for i := 1; i < attempt && d < r.maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, r.maxDelay)
+	return d - time.Duration(float64(d)*r.jitter*r.random())
+}
+
+// sleep waits for the duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRetryable reports whether a provider error is worth retrying: timeouts,
+// network errors and errors reporting themselves as temporary, such as
+// rate limits and server errors from the HTTP providers
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,178 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// Route is the path a routed conversion took
+type Route string
+
+const (
+	// RouteRules serves the rules answer without asking the AI
+	RouteRules Route = "rules"
+	// RouteAI asks the AI because no rule matched well enough
+	RouteAI Route = "ai"
+	// RouteVerify asks the AI to confirm a rules answer of middling quality
+	RouteVerify Route = "verify"
+)
+
+// RoutingDecision explains how a routed conversion was served, for tuning thresholds
+type RoutingDecision struct {
+	Route Route `json:"route"`
+	// Score is the match quality of the rules answer, 0 when no rule matched
+	Score           float64 `json:"score"`
+	AIThreshold     float64 `json:"ai_threshold"`
+	VerifyThreshold float64 `json:"verify_threshold,omitempty"`
+	// RulesExpression is the rules answer, empty when no rule matched
+	RulesExpression string `json:"rules_expression,omitempty"`
+	// Quality details the score, nil when no rule matched
+	Quality *rules.MatchQuality `json:"quality,omitempty"`
+	// Agreed reports whether the AI answer fires at the same times as the
+	// rules answer, on the verify route
+	Agreed bool `json:"agreed,omitempty"`
+	// AIError is why the AI answer could not be used when the rules answer
+	// was served in its place
+	AIError string `json:"ai_error,omitempty"`
+}
+
+// RouterOption represents a functional option for configuring routing
+type RouterOption func(*router)
+
+// WithAIThreshold sets the rules match score below which the AI is asked, 0.5 by default
+func WithAIThreshold(score float64) RouterOption {
+	return func(r *router) {
+		r.aiThreshold = score
+	}
+}
+
+// WithVerifyThreshold sets the rules match score below which the AI is asked
+// to confirm the rules answer. Scores from the AI threshold up to this one
+// take the verify route. Verification is off by default.
+func WithVerifyThreshold(score float64) RouterOption {
+	return func(r *router) {
+		r.verifyThreshold = score
+	}
+}
+
+// WithRouting decides per input whether the rules answer is good enough, from
+// the match quality the rules engine reports, instead of always preferring the
+// rules or the AI. Patterns are not anchored, so a rule may match half a
+// sentence; such answers score low and are sent to the AI. When the AI fails,
+// a rules answer is still served. Routing takes precedence over WithAIFirst.
+func WithRouting(options ...RouterOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		r := &router{aiThreshold: 0.5}
+		for _, option := range options {
+			option(r)
+		}
+		m.router = r
+	}
+}
+
+// router holds the routing thresholds
+type router struct {
+	aiThreshold     float64
+	verifyThreshold float64
+}
+
+// route picks the route for a rules match score
+func (r *router) route(matched bool, score float64) Route {
+	switch {
+	case !matched || score < r.aiThreshold:
+		return RouteAI
+	case score < r.verifyThreshold:
+		return RouteVerify
+	default:
+		return RouteRules
+	}
+}
+
+// String describes the thresholds, for cache keys
+func (r *router) String() string {
+	return fmt.Sprintf("route:%g:%g", r.aiThreshold, r.verifyThreshold)
+}
+
+// routed converts an expression along the route its rules match score picks
+func (m *BraveHumanCronMapper) routed(ctx context.Context, expression string, autoDetect bool) (*Result, error) {
+	convert := m.coreMapper.ConvertResult
+	if autoDetect {
+		convert = m.coreMapper.AutoDetectResult
+	}
+	rulesResult, rulesErr := convert(expression)
+
+	decision := &RoutingDecision{
+		AIThreshold:     m.router.aiThreshold,
+		VerifyThreshold: m.router.verifyThreshold,
+	}
+	if rulesErr == nil {
+		quality := rulesResult.Quality
+		decision.Score = quality.Score
+		decision.Quality = &quality
+		decision.RulesExpression = rulesResult.Expression
+	}
+	decision.Route = m.router.route(rulesErr == nil, decision.Score)
+
+	if decision.Route == RouteRules {
+		return routedRules(rulesResult, decision), nil
+	}
+
+	reason := rulesErr
+	if reason == nil {
+		reason = fmt.Errorf("rule %s matched the input with score %.2f", rulesResult.Rule, decision.Score)
+	}
+	result, err := m.generate(ctx, m.request(expression, autoDetect, reason))
+
+	if rulesErr != nil {
+		if err != nil {
+			if m.rulesOnly(err) {
+				return nil, rulesErr
+			}
+			return nil, fmt.Errorf("unable to convert expression with local rules or AI: %s: %w", expression, err)
+		}
+		result.Routing = decision
+		return result, nil
+	}
+
+	if err != nil {
+		// A doubtful rules answer beats none
+		decision.AIError = err.Error()
+		return routedRules(rulesResult, decision), nil
+	}
+
+	if decision.Route == RouteVerify {
+		decision.Agreed = sameSchedule(rulesResult.Expression, result.Expression)
+		if decision.Agreed {
+			confirmed := routedRules(rulesResult, decision)
+			confirmed.AI = result.AI
+			return confirmed, nil
+		}
+	}
+
+	result.Routing = decision
+	return result, nil
+}
+
+// routedRules is the rules outcome with its routing decision
+func routedRules(result *core.Result, decision *RoutingDecision) *Result {
+	outcome := rulesOutcome(result)
+	outcome.Routing = decision
+	return outcome
+}
+
+// sameSchedule reports whether two expressions fire at the same times
+func sameSchedule(a, b string) bool {
+	x, err := cron.Parse(a)
+	if err != nil {
+		return false
+	}
+	y, err := cron.Parse(b)
+	if err != nil {
+		return false
+	}
+	return x.Equivalent(y)
+}
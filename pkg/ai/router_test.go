@@ -0,0 +1,97 @@
+package ai_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+	"github.com/flaticols/cronscribe/pkg/ai/aitest"
+)
+
+func TestRouting(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		reply    aitest.Reply
+		want     string
+		source   ai.Source
+		route    ai.Route
+		calls    int
+		agreed   bool
+		fallback bool
+	}{
+		{"full match", "every day at 9:30", aitest.Answer("0 0 * * *"), "30 9 * * *", ai.SourceRules, ai.RouteRules, 0, false, false},
+		{"partial match", "on workdays, every day at 9:30", aitest.Answer("30 9 * * 1-5"), "30 9 * * 1-5", ai.SourceAI, ai.RouteAI, 1, false, false},
+		{"partial match, ai fails", "on workdays, every day at 9:30", aitest.Fail(errOffline), "30 9 * * *", ai.SourceRules, ai.RouteAI, 1, false, true},
+		{"no match", "business mornings", aitest.Answer("0 9 * * 1-5"), "0 9 * * 1-5", ai.SourceAI, ai.RouteAI, 1, false, false},
+		{"verify agrees", "every monday at 9am", aitest.Answer("0 9 * * MON"), "0 9 * * 1", ai.SourceRules, ai.RouteVerify, 1, true, false},
+		{"verify disagrees", "every monday at 9am", aitest.Answer("0 21 * * 1"), "0 21 * * 1", ai.SourceAI, ai.RouteVerify, 1, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := aitest.NewFake().Otherwise(tt.reply)
+			mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil,
+				ai.WithScheduleProvider(fake),
+				ai.WithAIFirst(true), // ignored with routing
+				ai.WithRouting(ai.WithAIThreshold(0.7), ai.WithVerifyThreshold(0.926)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := mapper.ToCronResult(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decision := result.Routing
+			if result.Expression != tt.want || result.Source != tt.source || decision == nil {
+				t.Fatalf("result = %s from %s, routing %+v, want %s from %s", result.Expression, result.Source, decision, tt.want, tt.source)
+			}
+			if decision.Route != tt.route || decision.Agreed != tt.agreed || (decision.AIError != "") != tt.fallback {
+				t.Errorf("routing = %+v", decision)
+			}
+			if decision.AIThreshold != 0.7 || decision.VerifyThreshold != 0.926 {
+				t.Errorf("thresholds not reported: %+v", decision)
+			}
+			if fake.Calls("") != tt.calls {
+				t.Errorf("provider called %d times, want %d", fake.Calls(""), tt.calls)
+			}
+		})
+	}
+}
+
+func TestRoutingScores(t *testing.T) {
+	fake := aitest.NewFake().Otherwise(aitest.Answer("0 9 * * 1-5"))
+	mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil,
+		ai.WithScheduleProvider(fake),
+		ai.WithRouting(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := mapper.AutoDetectResult(context.Background(), "elke dag om 9 vm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial, err := mapper.AutoDetectResult(context.Background(), "on workdays, every day at 9:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The default threshold keeps partial matches of most of the input
+	if full.Routing.Route != ai.RouteRules || partial.Routing.Route != ai.RouteRules || fake.Calls("") != 0 {
+		t.Errorf("routes = %s, %s", full.Routing.Route, partial.Routing.Route)
+	}
+	if full.Routing.Score <= partial.Routing.Score || full.Routing.Quality.Coverage != 1 {
+		t.Errorf("scores = %+v, %+v", full.Routing, partial.Routing)
+	}
+	if leftover := partial.Routing.Quality.Leftover; len(leftover) != 2 || leftover[1] != "workdays" {
+		t.Errorf("leftover = %q", leftover)
+	}
+	if partial.Routing.RulesExpression != "30 9 * * *" {
+		t.Errorf("rules expression = %q", partial.Routing.RulesExpression)
+	}
+}
@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+// ScheduleRequest is everything a provider may use to convert a schedule description
+type ScheduleRequest struct {
+	// Input is the human-readable schedule description
+	Input string
+	// Language is the language set on the mapper, empty when auto-detecting
+	Language string
+	// Dialect is the cron flavour the answer is expected in
+	Dialect cron.Dialect
+	// Location is the time zone of the description, nil when unspecified
+	Location *time.Location
+	// RulesError is why the rules engine could not convert the input, nil
+	// when the rules were not tried before the provider
+	RulesError error
+	// Examples are inputs of the loaded rules with their expressions, for
+	// few-shot prompts
+	Examples []Example
+}
+
+// Example is an input with the cron expression it converts to
+type Example struct {
+	Input string `json:"input"`
+	Cron  string `json:"cron"`
+}
+
+// Usage reports the tokens a provider spent on a request
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ScheduleResponse is the answer of a ScheduleProvider
+type ScheduleResponse struct {
+	// Expression is the cron expression, or a text containing it
+	Expression string `json:"expression"`
+	// Explanation is the model's description of the schedule, if any
+	Explanation string `json:"explanation,omitempty"`
+	// Confidence is the model's confidence between 0 and 1, 0 when unknown
+	Confidence float64 `json:"confidence,omitempty"`
+	// Model identifies the model that answered
+	Model string `json:"model,omitempty"`
+	Usage Usage  `json:"usage"`
+	// Structured is the schedule the model filled in structured output mode
+	Structured *StructuredSchedule `json:"structured,omitempty"`
+}
+
+// ScheduleProvider is a richer interface for services that generate cron
+// expressions, receiving the context of the conversion and reporting details
+type ScheduleProvider interface {
+	// GenerateSchedule generates a cron expression for the request
+	GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error)
+}
+
+// AdaptProvider turns an AIProvider into a ScheduleProvider. The wrapped
+// provider only receives the input text.
+func AdaptProvider(provider AIProvider) ScheduleProvider {
+	if sp, ok := provider.(ScheduleProvider); ok {
+		return sp
+	}
+	return &providerAdapter{provider: provider}
+}
+
+// providerAdapter calls an AIProvider for a ScheduleProvider
+type providerAdapter struct {
+	provider AIProvider
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (a *providerAdapter) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	expression, err := a.provider.GenerateCron(ctx, req.Input)
+	if err != nil {
+		return nil, err
+	}
+	return &ScheduleResponse{Expression: expression}, nil
+}
+
+// generateCron implements AIProvider on top of a ScheduleProvider
+func generateCron(ctx context.Context, provider ScheduleProvider, input string) (string, error) {
+	resp, err := provider.GenerateSchedule(ctx, ScheduleRequest{Input: input})
+	if err != nil {
+		return "", err
+	}
+	return resp.Expression, nil
+}
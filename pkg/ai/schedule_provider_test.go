@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+type recordingProvider struct {
+	requests []ScheduleRequest
+	response ScheduleResponse
+}
+
+func (p *recordingProvider) GenerateSchedule(_ context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	p.requests = append(p.requests, req)
+	response := p.response
+	return &response, nil
+}
+
+func TestScheduleProviderRequest(t *testing.T) {
+	provider := &recordingProvider{response: ScheduleResponse{
+		Expression:  "```\n0 9 * * 1-5\n```",
+		Explanation: "weekdays at nine",
+		Model:       "test-model",
+	}}
+
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mapper.SetLanguage("nl"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mapper.ToCronResult(context.Background(), "werkdagen om negen uur")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Source != SourceAI || result.Expression != "0 9 * * 1-5" || result.AI.Model != "test-model" {
+		t.Errorf("ToCronResult() = %+v", result)
+	}
+
+	req := provider.requests[0]
+	if req.Language != "nl" || req.Dialect.Name != "standard" || !errors.Is(req.RulesError, rules.ErrNoMatch) {
+		t.Errorf("request = %+v", req)
+	}
+
+	result, err = mapper.ToCronResult(context.Background(), "elke dag om 9 vm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Source != SourceRules || result.Rules.Rule != "daily_at_time" || len(provider.requests) != 1 {
+		t.Errorf("ToCronResult() = %+v, want rules result without calling the provider", result)
+	}
+}
+
+type textProvider string
+
+func (p textProvider) GenerateCron(context.Context, string) (string, error) {
+	return string(p), nil
+}
+
+func TestAdaptProvider(t *testing.T) {
+	response, err := AdaptProvider(textProvider("0 0 * * *")).GenerateSchedule(context.Background(), ScheduleRequest{Input: "daily"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Expression != "0 0 * * *" {
+		t.Errorf("Expression = %q", response.Expression)
+	}
+}
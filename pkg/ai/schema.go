@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSchema generates a JSON Schema for a Go value's type from its struct
+// tags, for function-calling and structured output APIs. The json tag names
+// the properties, fields without omitempty are required, and the description,
+// enum, minimum and maximum tags describe the values; on slices they apply to
+// the items. Structs, slices, strings, booleans, integers and floats are
+// supported.
+func JSONSchema(v any) (map[string]any, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("cannot generate a JSON Schema for nil")
+	}
+	return typeSchema(t)
+}
+
+// typeSchema builds the schema of a type
+func typeSchema(t reflect.Type) (map[string]any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	}
+	return nil, fmt.Errorf("cannot generate a JSON Schema for type %s", t)
+}
+
+// structSchema builds the schema of a struct from its exported fields
+func structSchema(t reflect.Type) (map[string]any, error) {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema, err := typeSchema(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+
+		// Value constraints describe the items of lists
+		values := schema
+		if items, ok := schema["items"].(map[string]any); ok {
+			values = items
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values["enum"] = strings.Split(enum, ",")
+		}
+		for _, bound := range []string{"minimum", "maximum"} {
+			if tag := field.Tag.Get(bound); tag != "" {
+				n, err := strconv.Atoi(tag)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: invalid %s %q", field.Name, bound, tag)
+				}
+				values[bound] = n
+			}
+		}
+
+		properties[name] = schema
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
@@ -0,0 +1,229 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+)
+
+// ShadowReport compares the rules answer for an input with the answer the AI
+// gave in the background
+type ShadowReport struct {
+	Input string
+	// Rules is the rules answer, nil when no rule matched
+	Rules *core.Result
+	// RulesError is why the rules could not convert the input
+	RulesError error
+	// AI is the AI answer, nil when the AI failed
+	AI *Result
+	// AIError is why the AI could not answer
+	AIError error
+	// Equal reports whether both answers fire at the same times
+	Equal bool
+	// Duration is how long the AI took
+	Duration time.Duration
+}
+
+// ShadowReporter receives the outcome of every shadow conversion. Reports
+// arrive from background goroutines, concurrently.
+type ShadowReporter interface {
+	Report(report ShadowReport)
+}
+
+// ShadowReporterFunc adapts a function to the ShadowReporter interface
+type ShadowReporterFunc func(report ShadowReport)
+
+// Report implements the ShadowReporter interface
+func (f ShadowReporterFunc) Report(report ShadowReport) {
+	f(report)
+}
+
+// ShadowStats holds the counters of shadow conversions
+type ShadowStats struct {
+	// Started is the number of shadow conversions started
+	Started int64 `json:"started"`
+	// Dropped is the number of inputs skipped because all slots were busy
+	Dropped int64 `json:"dropped"`
+	// Agreed and Disagreed count the conversions both engines answered
+	Agreed    int64 `json:"agreed"`
+	Disagreed int64 `json:"disagreed"`
+	// AIFailures is the number of conversions the AI could not answer
+	AIFailures int64 `json:"ai_failures"`
+	// InFlight is the number of shadow conversions running
+	InFlight int `json:"in_flight"`
+}
+
+// ShadowOption represents a functional option for configuring shadow mode
+type ShadowOption func(*shadow)
+
+// WithShadowConcurrency sets how many shadow conversions may run at once, 4 by
+// default. Inputs arriving while all slots are busy are not shadowed.
+func WithShadowConcurrency(n int) ShadowOption {
+	return func(s *shadow) {
+		if n > 0 {
+			s.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithShadowTimeout limits how long a shadow conversion may take, 30s by default
+func WithShadowTimeout(timeout time.Duration) ShadowOption {
+	return func(s *shadow) {
+		s.timeout = timeout
+	}
+}
+
+// WithShadow answers every conversion with the rules only, and runs the AI in
+// the background to report whether it agrees. The AI never adds latency or
+// errors to conversions, and it only runs for inputs the cache did not serve.
+// Shadow mode takes precedence over WithAIFirst and WithRouting.
+func WithShadow(reporter ShadowReporter, options ...ShadowOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		s := &shadow{
+			reporter: reporter,
+			slots:    make(chan struct{}, 4),
+			timeout:  30 * time.Second,
+		}
+		for _, option := range options {
+			option(s)
+		}
+		m.shadow = s
+	}
+}
+
+// shadow runs AI conversions in the background with bounded concurrency
+type shadow struct {
+	reporter ShadowReporter
+	slots    chan struct{}
+	timeout  time.Duration
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	stats ShadowStats
+}
+
+// shadowed answers with the rules and starts the AI conversion of the input in
+// the background
+func (m *BraveHumanCronMapper) shadowed(ctx context.Context, expression string, autoDetect bool) (*Result, error) {
+	convert := m.coreMapper.ConvertResult
+	if autoDetect {
+		convert = m.coreMapper.AutoDetectResult
+	}
+	rulesResult, rulesErr := convert(expression)
+
+	if m.shadow.acquire() {
+		// The request captures the mapper settings before the caller can change them
+		req := m.request(expression, autoDetect, rulesErr)
+		m.shadow.start(ctx, func(ctx context.Context) ShadowReport {
+			return m.compare(ctx, req, rulesResult, rulesErr)
+		})
+	}
+
+	if rulesErr != nil {
+		return nil, rulesErr
+	}
+	return rulesOutcome(rulesResult), nil
+}
+
+// compare asks the AI and compares its answer with the rules answer
+func (m *BraveHumanCronMapper) compare(ctx context.Context, req ScheduleRequest, rulesResult *core.Result, rulesErr error) ShadowReport {
+	report := ShadowReport{Input: req.Input, Rules: rulesResult, RulesError: rulesErr}
+
+	start := time.Now()
+	report.AI, report.AIError = m.generate(ctx, req)
+	report.Duration = time.Since(start)
+
+	if rulesErr == nil && report.AIError == nil {
+		report.Equal = sameSchedule(rulesResult.Expression, report.AI.Expression)
+	}
+	return report
+}
+
+// acquire takes a slot for a shadow conversion, without waiting
+func (s *shadow) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case s.slots <- struct{}{}:
+		s.stats.Started++
+		return true
+	default:
+		s.stats.Dropped++
+		return false
+	}
+}
+
+// start runs a shadow conversion in an acquired slot. The conversion keeps
+// the values of the caller's context, such as the caller key, but not its
+// cancellation.
+func (s *shadow) start(ctx context.Context, run func(ctx context.Context) ShadowReport) {
+	ctx = context.WithoutCancel(ctx)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.slots }()
+
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+
+		report := s.protect(func() ShadowReport { return run(ctx) })
+		s.count(report)
+		s.protect(func() ShadowReport {
+			s.reporter.Report(report)
+			return report
+		})
+	}()
+}
+
+// protect keeps a panic in a shadow conversion or a reporter from crashing
+// the program, reporting it as an AI failure
+func (s *shadow) protect(fn func() ShadowReport) (report ShadowReport) {
+	defer func() {
+		if r := recover(); r != nil {
+			report.AI = nil
+			report.AIError = fmt.Errorf("shadow conversion panicked: %v", r)
+		}
+	}()
+	return fn()
+}
+
+func (s *shadow) count(report ShadowReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case report.AIError != nil:
+		s.stats.AIFailures++
+	case report.RulesError != nil:
+	case report.Equal:
+		s.stats.Agreed++
+	default:
+		s.stats.Disagreed++
+	}
+}
+
+// ShadowStats returns the shadow mode counters, zero when shadow mode is off
+func (m *BraveHumanCronMapper) ShadowStats() ShadowStats {
+	if m.shadow == nil {
+		return ShadowStats{}
+	}
+
+	m.shadow.mu.Lock()
+	defer m.shadow.mu.Unlock()
+
+	stats := m.shadow.stats
+	stats.InFlight = len(m.shadow.slots)
+	return stats
+}
+
+// WaitShadow waits for the running shadow conversions to finish and their
+// reports to be delivered, for graceful shutdowns and tests
+func (m *BraveHumanCronMapper) WaitShadow() {
+	if m.shadow != nil {
+		m.shadow.wg.Wait()
+	}
+}
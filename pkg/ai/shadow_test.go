@@ -0,0 +1,139 @@
+package ai_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/ai"
+	"github.com/flaticols/cronscribe/pkg/ai/aitest"
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// reports collects shadow reports, safe for concurrent use
+type reports struct {
+	mu   sync.Mutex
+	list []ai.ShadowReport
+}
+
+func (r *reports) Report(report ai.ShadowReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.list = append(r.list, report)
+}
+
+func (r *reports) byInput() map[string]ai.ShadowReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := make(map[string]ai.ShadowReport)
+	for _, report := range r.list {
+		m[report.Input] = report
+	}
+	return m
+}
+
+func TestShadow(t *testing.T) {
+	fake := aitest.NewFake().
+		On("every day at 9:30", aitest.Answer("30 9 * * *")).
+		On("every monday at 9am", aitest.Answer("0 21 * * 1")).
+		On("every 5 minutes", aitest.Fail(errOffline)).
+		On("business mornings", aitest.Answer("0 9 * * 1-5"))
+
+	collected := &reports{}
+	mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil,
+		ai.WithScheduleProvider(fake),
+		ai.WithAIFirst(true), // ignored in shadow mode
+		ai.WithShadow(collected, ai.WithShadowConcurrency(10)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for input, want := range map[string]string{
+		"every day at 9:30":   "30 9 * * *",
+		"every monday at 9am": "0 9 * * 1",
+		"every 5 minutes":     "*/5 * * * *",
+	} {
+		result, err := mapper.ToCronResult(context.Background(), input)
+		if err != nil || result.Expression != want || result.Source != ai.SourceRules {
+			t.Errorf("ToCronResult(%q) = %+v, %v, want %s from the rules", input, result, err, want)
+		}
+	}
+	// Without a rule the answer is the rules error, even though the AI knows better
+	if _, err := mapper.AutoDetect("business mornings"); !errors.Is(err, rules.ErrNoMatch) {
+		t.Errorf("AutoDetect() error = %v, want the rules error", err)
+	}
+
+	mapper.WaitShadow()
+	got := collected.byInput()
+	if len(got) != 4 {
+		t.Fatalf("got %d reports", len(got))
+	}
+	if r := got["every day at 9:30"]; !r.Equal || r.Rules == nil || r.AI == nil {
+		t.Errorf("agreement report = %+v", r)
+	}
+	if r := got["every monday at 9am"]; r.Equal || r.AI.Expression != "0 21 * * 1" {
+		t.Errorf("disagreement report = %+v", r)
+	}
+	if r := got["every 5 minutes"]; !errors.Is(r.AIError, errOffline) || r.Equal {
+		t.Errorf("AI failure report = %+v", r)
+	}
+	if r := got["business mornings"]; !errors.Is(r.RulesError, rules.ErrNoMatch) || r.AI.Expression != "0 9 * * 1-5" {
+		t.Errorf("rules failure report = %+v", r)
+	}
+
+	stats := mapper.ShadowStats()
+	want := ai.ShadowStats{Started: 4, Agreed: 1, Disagreed: 1, AIFailures: 1}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestShadowAddsNoLatency(t *testing.T) {
+	fake := aitest.NewFake().Otherwise(aitest.Answer("30 9 * * *").After(time.Minute))
+
+	var panicked sync.WaitGroup
+	panicked.Add(1)
+	reporter := ai.ShadowReporterFunc(func(report ai.ShadowReport) {
+		defer panicked.Done()
+		if !errors.Is(report.AIError, context.DeadlineExceeded) {
+			t.Errorf("AIError = %v, want deadline exceeded", report.AIError)
+		}
+		panic("broken reporter")
+	})
+
+	mapper, err := ai.NewBraveHumanCronMapper("../core/rules", nil,
+		ai.WithScheduleProvider(fake),
+		ai.WithShadow(reporter, ai.WithShadowConcurrency(1), ai.WithShadowTimeout(200*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The caller's context ending does not cut the shadow conversion short
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	for range 3 {
+		if _, err := mapper.ToCronResult(ctx, "every day at 9:30"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("conversions took %s", elapsed)
+	}
+
+	// One slot: the other conversions were not shadowed
+	if stats := mapper.ShadowStats(); stats.Started != 1 || stats.Dropped != 2 || stats.InFlight != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	mapper.WaitShadow()
+	panicked.Wait()
+	if stats := mapper.ShadowStats(); stats.AIFailures != 1 || stats.InFlight != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
@@ -0,0 +1,650 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/flaticols/cronscribe/pkg/core/schedule"
+)
+
+// StructuredToolName is the function structured providers ask the model to call
+const StructuredToolName = "set_schedule"
+
+// StructuredSchedule is a schedule described field by field instead of in
+// cron syntax, which models fill far more reliably than they write cron.
+//
+// Empty fields finer than the finest field set are 0, the others match every
+// value: {"hours": [9]} is 09:00 every day, {"weekdays": ["monday"]} is
+// midnight every Monday and {"minutes": [30]} is half past every hour.
+type StructuredSchedule struct {
+	Minutes        []int       `json:"minutes,omitempty" description:"Minutes of the hour the schedule fires at" minimum:"0" maximum:"59"`
+	MinuteInterval int         `json:"minute_interval,omitempty" description:"Fire every N minutes, instead of listing minutes" minimum:"1" maximum:"59"`
+	Hours          []int       `json:"hours,omitempty" description:"Hours of the day the schedule fires at, 24-hour clock" minimum:"0" maximum:"23"`
+	HourInterval   int         `json:"hour_interval,omitempty" description:"Fire every N hours, instead of listing hours" minimum:"1" maximum:"23"`
+	Days           []int       `json:"days,omitempty" description:"Days of the month the schedule fires on" minimum:"1" maximum:"31"`
+	LastDayOfMonth bool        `json:"last_day_of_month,omitempty" description:"Fire on the last day of every month"`
+	Months         []int       `json:"months,omitempty" description:"Months the schedule fires in, 1 is January" minimum:"1" maximum:"12"`
+	Weekdays       []string    `json:"weekdays,omitempty" description:"Days of the week the schedule fires on" enum:"monday,tuesday,wednesday,thursday,friday,saturday,sunday"`
+	NthWeekday     *NthWeekday `json:"nth_weekday,omitempty" description:"Fire on one occurrence of a weekday in the month, such as the second Tuesday"`
+	Timezone       string      `json:"timezone,omitempty" description:"IANA time zone of the times, such as Europe/Amsterdam; omit when none is mentioned"`
+}
+
+// NthWeekday is an occurrence of a weekday within a month
+type NthWeekday struct {
+	Weekday string `json:"weekday" description:"Day of the week" enum:"monday,tuesday,wednesday,thursday,friday,saturday,sunday"`
+	N       int    `json:"n" description:"Occurrence in the month, 1 to 5, or -1 for the last one" minimum:"-1" maximum:"5"`
+}
+
+// StructuredError is returned for structured schedules with invalid fields
+type StructuredError struct {
+	Field  string
+	Reason string
+}
+
+func (e *StructuredError) Error() string {
+	return fmt.Sprintf("invalid structured schedule: %s: %s", e.Field, e.Reason)
+}
+
+// weekdayNumbers maps weekday names to cron numbers
+var weekdayNumbers = map[string]int{
+	"sunday": 0, "monday": 1, "tuesday": 2, "wednesday": 3,
+	"thursday": 4, "friday": 5, "saturday": 6,
+}
+
+// StructuredScheduleSchema returns the JSON Schema of StructuredSchedule
+func StructuredScheduleSchema() map[string]any {
+	schema, err := JSONSchema(StructuredSchedule{})
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Validate checks the ranges and combinations of the fields
+func (s *StructuredSchedule) Validate() error {
+	_, err := s.fields()
+	return err
+}
+
+// Expression converts the schedule to a cron expression
+func (s *StructuredSchedule) Expression() (*cron.Expression, error) {
+	fields, err := s.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := cron.Parse(strings.Join(fields[:], " "))
+	if err != nil {
+		return nil, fmt.Errorf("invalid structured schedule: %w", err)
+	}
+
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return nil, &StructuredError{Field: "timezone", Reason: fmt.Sprintf("unknown time zone %s", s.Timezone)}
+		}
+		expr.Location = loc
+	}
+	return expr, nil
+}
+
+// Schedule converts the structured schedule to a typed schedule
+func (s *StructuredSchedule) Schedule() (*schedule.Schedule, error) {
+	expr, err := s.Expression()
+	if err != nil {
+		return nil, err
+	}
+	return schedule.FromExpression(expr)
+}
+
+// Render converts the schedule to a cron expression written in the dialect
+func (s *StructuredSchedule) Render(dialect cron.Dialect) (string, error) {
+	expr, err := s.Expression()
+	if err != nil {
+		return "", err
+	}
+	return dialect.Render(expr)
+}
+
+// fields builds the five cron fields
+func (s *StructuredSchedule) fields() ([5]string, error) {
+	var fields [5]string
+
+	minute, err := timeField("minutes", s.Minutes, s.MinuteInterval, 59)
+	if err != nil {
+		return fields, err
+	}
+	hour, err := timeField("hours", s.Hours, s.HourInterval, 23)
+	if err != nil {
+		return fields, err
+	}
+
+	var days []string
+	for _, d := range s.Days {
+		if d < 1 || d > 31 {
+			return fields, &StructuredError{Field: "days", Reason: fmt.Sprintf("day %d out of range 1-31", d)}
+		}
+		days = append(days, strconv.Itoa(d))
+	}
+	if s.LastDayOfMonth {
+		days = append(days, "L")
+	}
+
+	var months []string
+	for _, m := range s.Months {
+		if m < 1 || m > 12 {
+			return fields, &StructuredError{Field: "months", Reason: fmt.Sprintf("month %d out of range 1-12", m)}
+		}
+		months = append(months, strconv.Itoa(m))
+	}
+
+	var weekdays []string
+	for _, name := range s.Weekdays {
+		n, ok := weekdayNumbers[strings.ToLower(name)]
+		if !ok {
+			return fields, &StructuredError{Field: "weekdays", Reason: fmt.Sprintf("unknown weekday %q", name)}
+		}
+		weekdays = append(weekdays, strconv.Itoa(n))
+	}
+	if nth := s.NthWeekday; nth != nil {
+		n, ok := weekdayNumbers[strings.ToLower(nth.Weekday)]
+		if !ok {
+			return fields, &StructuredError{Field: "nth_weekday", Reason: fmt.Sprintf("unknown weekday %q", nth.Weekday)}
+		}
+		switch {
+		case nth.N == -1:
+			weekdays = append(weekdays, fmt.Sprintf("%dL", n))
+		case nth.N >= 1 && nth.N <= 5:
+			weekdays = append(weekdays, fmt.Sprintf("%d#%d", n, nth.N))
+		default:
+			return fields, &StructuredError{Field: "nth_weekday", Reason: fmt.Sprintf("occurrence %d out of range, expected 1-5 or -1", nth.N)}
+		}
+	}
+
+	dated := len(days) > 0 || len(months) > 0 || len(weekdays) > 0
+	fields[0] = orDefault(minute, hour != "" || dated)
+	fields[1] = orDefault(hour, minute == "" && dated)
+	fields[2] = strings.Join(days, ",")
+	fields[3] = strings.Join(months, ",")
+	fields[4] = strings.Join(weekdays, ",")
+	for i := 2; i < 5; i++ {
+		fields[i] = orDefault(fields[i], false)
+	}
+	return fields, nil
+}
+
+// timeField builds a minute or hour field from a list or an interval
+func timeField(name string, values []int, interval, limit int) (string, error) {
+	if len(values) > 0 && interval > 0 {
+		return "", &StructuredError{Field: name, Reason: "both a list and an interval are set"}
+	}
+	if interval < 0 || interval > limit {
+		return "", &StructuredError{Field: name, Reason: fmt.Sprintf("interval %d out of range 1-%d", interval, limit)}
+	}
+	if interval > 0 {
+		return fmt.Sprintf("*/%d", interval), nil
+	}
+
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if v < 0 || v > limit {
+			return "", &StructuredError{Field: name, Reason: fmt.Sprintf("value %d out of range 0-%d", v, limit)}
+		}
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// orDefault returns the field, or 0 when a coarser field is set and * otherwise
+func orDefault(field string, zero bool) string {
+	switch {
+	case field != "":
+		return field
+	case zero:
+		return "0"
+	default:
+		return "*"
+	}
+}
+
+// ParseStructuredSchedule reads a structured schedule from a model's answer.
+// It tolerates models that ignore the schema: the object may be wrapped in
+// code fences, prose or an arguments envelope, keys may use other spellings
+// (minute, dayOfWeek, tz), single values may replace lists, numbers may be
+// quoted and weekdays and months may be names, abbreviations or numbers.
+func ParseStructuredSchedule(text string) (*StructuredSchedule, error) {
+	raw, ok := findObject(text)
+	if !ok {
+		return nil, fmt.Errorf("no JSON object in %q", text)
+	}
+
+	object, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	object = unwrapArguments(object)
+
+	values, err := collectFields(object)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StructuredSchedule{}
+	for _, name := range structuredFields {
+		if value, ok := values[name]; ok {
+			if err := s.set(name, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// findObject returns the first balanced JSON object in the text
+func findObject(text string) (string, bool) {
+	for start := strings.IndexByte(text, '{'); start >= 0; {
+		depth, inString, escaped := 0, false, false
+		for i := start; i < len(text); i++ {
+			c := text[i]
+			switch {
+			case escaped:
+				escaped = false
+			case inString && c == '\\':
+				escaped = true
+			case c == '"':
+				inString = !inString
+			case inString:
+			case c == '{':
+				depth++
+			case c == '}':
+				depth--
+				if depth == 0 {
+					candidate := text[start : i+1]
+					if json.Valid([]byte(candidate)) {
+						return candidate, true
+					}
+					i = len(text)
+				}
+			}
+		}
+
+		next := strings.IndexByte(text[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return "", false
+}
+
+// decodeObject decodes a JSON object keeping numbers exact
+func decodeObject(raw string) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("invalid structured schedule: %w", err)
+	}
+	return object, nil
+}
+
+// unwrapArguments returns the schedule inside envelopes such as
+// {"name": "set_schedule", "arguments": {...}} or {"schedule": {...}}
+func unwrapArguments(object map[string]any) map[string]any {
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		value := object[key]
+		switch normalizeKey(key) {
+		case "arguments", "parameters", "schedule", "input":
+		default:
+			continue
+		}
+
+		switch inner := value.(type) {
+		case map[string]any:
+			return unwrapArguments(inner)
+		case string:
+			if nested, err := decodeObject(inner); err == nil {
+				return unwrapArguments(nested)
+			}
+		}
+	}
+	return object
+}
+
+// keyAliases maps normalized key spellings to the schema's names
+var keyAliases = map[string]string{
+	"minute": "minutes", "minutes": "minutes",
+	"minuteinterval": "minute_interval", "minutestep": "minute_interval", "everyminutes": "minute_interval",
+	"intervalminutes": "minute_interval", "minuteevery": "minute_interval",
+	"hour": "hours", "hours": "hours",
+	"hourinterval": "hour_interval", "hourstep": "hour_interval", "everyhours": "hour_interval",
+	"intervalhours": "hour_interval", "hourevery": "hour_interval",
+	"day": "days", "days": "days", "dayofmonth": "days", "daysofmonth": "days", "monthdays": "days", "dom": "days",
+	"lastday": "last_day_of_month", "lastdayofmonth": "last_day_of_month",
+	"month": "months", "months": "months",
+	"weekday": "weekdays", "weekdays": "weekdays", "dayofweek": "weekdays", "daysofweek": "weekdays", "dow": "weekdays",
+	"nthweekday": "nth_weekday", "nth": "nth_weekday",
+	"timezone": "timezone", "tz": "timezone", "zone": "timezone", "timezonename": "timezone",
+}
+
+// structuredFields lists the schema's fields in the order they are set, so
+// minutes written as a step meet minute_interval the same way every time
+var structuredFields = []string{
+	"minutes", "minute_interval", "hours", "hour_interval", "days",
+	"last_day_of_month", "months", "weekdays", "nth_weekday", "timezone",
+}
+
+// collectFields maps the keys of an object to the schema's names. Unset values
+// such as nulls are skipped, so they never hide a value under another alias,
+// and aliases that read as different values are reported.
+func collectFields(object map[string]any) (map[string]any, error) {
+	values := make(map[string]any)
+	sources := make(map[string]string)
+
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		name, ok := keyAliases[normalizeKey(key)]
+		value := object[key]
+		if !ok || isUnset(value) {
+			continue
+		}
+
+		previous, seen := sources[name]
+		if !seen {
+			values[name], sources[name] = value, key
+			continue
+		}
+
+		var a, b StructuredSchedule
+		errA, errB := a.set(name, values[name]), b.set(name, value)
+		if errA != nil || errB != nil || !reflect.DeepEqual(a, b) {
+			return nil, &StructuredError{Field: name, Reason: fmt.Sprintf("%q and %q disagree", previous, key)}
+		}
+	}
+	return values, nil
+}
+
+// isUnset reports whether a value leaves its field unset: null, an empty
+// string or list, a wildcard or false
+func isUnset(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		v = strings.TrimSpace(v)
+		return v == "" || v == "*"
+	case []any:
+		return len(v) == 0
+	case bool:
+		return !v
+	}
+	return false
+}
+
+// normalizeKey lowercases a key and drops everything but letters and digits
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, key)
+}
+
+// set assigns a decoded value to a field of the schema
+func (s *StructuredSchedule) set(name string, value any) error {
+	var err error
+	switch name {
+	case "minutes":
+		if step, ok := looseStep(value); ok {
+			return setInterval("minute_interval", &s.MinuteInterval, step)
+		}
+		s.Minutes, err = looseInts(name, value, nil)
+	case "minute_interval":
+		var n int
+		if n, err = looseInt(name, value, nil); err == nil {
+			err = setInterval(name, &s.MinuteInterval, n)
+		}
+	case "hours":
+		if step, ok := looseStep(value); ok {
+			return setInterval("hour_interval", &s.HourInterval, step)
+		}
+		s.Hours, err = looseInts(name, value, nil)
+	case "hour_interval":
+		var n int
+		if n, err = looseInt(name, value, nil); err == nil {
+			err = setInterval(name, &s.HourInterval, n)
+		}
+	case "days":
+		s.Days, err = looseInts(name, value, nil)
+	case "last_day_of_month":
+		s.LastDayOfMonth, err = looseBool(name, value)
+	case "months":
+		s.Months, err = looseInts(name, value, monthNumbers)
+	case "weekdays":
+		s.Weekdays, err = looseWeekdays(name, value)
+	case "nth_weekday":
+		s.NthWeekday, err = looseNth(value)
+	case "timezone":
+		s.Timezone, _ = value.(string)
+	}
+	return err
+}
+
+// setInterval sets an interval unless it is zero, and reports a different
+// interval already set through the step syntax of another field
+func setInterval(field string, target *int, n int) error {
+	if n == 0 {
+		return nil
+	}
+	if *target != 0 && *target != n {
+		return &StructuredError{Field: field, Reason: fmt.Sprintf("interval %d conflicts with %d", n, *target)}
+	}
+	*target = n
+	return nil
+}
+
+// monthNumbers maps month names and abbreviations to numbers
+var monthNumbers = map[string]int{
+	"january": 1, "february": 2, "march": 3, "april": 4, "may": 5, "june": 6,
+	"july": 7, "august": 8, "september": 9, "october": 10, "november": 11, "december": 12,
+}
+
+// weekdayNames lists the weekday names by cron number
+var weekdayNames = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// lookupName finds a name, or an abbreviation of at least three letters
+func lookupName(names map[string]int, text string) (int, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if n, ok := names[text]; ok {
+		return n, true
+	}
+	if len(text) >= 3 {
+		for name, n := range names {
+			if strings.HasPrefix(name, text) {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// looseList splits a value into its items: arrays, comma-separated strings
+// and single values. Wildcards and nulls are empty lists.
+func looseList(value any) []any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" || v == "*" || strings.EqualFold(v, "every") || strings.EqualFold(v, "any") {
+			return nil
+		}
+		var items []any
+		for _, part := range strings.Split(v, ",") {
+			items = append(items, strings.TrimSpace(part))
+		}
+		return items
+	}
+	return []any{value}
+}
+
+// looseStep reads a cron step such as */15 given instead of a list
+func looseStep(value any) (int, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+	step, ok := strings.CutPrefix(strings.TrimSpace(text), "*/")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(step)
+	return n, err == nil
+}
+
+// looseInt reads a number, a quoted number or, with names, a name
+func looseInt(field string, value any, names map[string]int) (int, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		if n, err := strconv.Atoi(v.String()); err == nil {
+			return n, nil
+		}
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	case string:
+		text := strings.TrimSpace(v)
+		if n, err := strconv.Atoi(text); err == nil {
+			return n, nil
+		}
+		if n, ok := lookupName(names, text); ok {
+			return n, nil
+		}
+	}
+	return 0, &StructuredError{Field: field, Reason: fmt.Sprintf("unexpected value %v", value)}
+}
+
+// looseInts reads a list of numbers
+func looseInts(field string, value any, names map[string]int) ([]int, error) {
+	var values []int
+	for _, item := range looseList(value) {
+		n, err := looseInt(field, item, names)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// looseBool reads a boolean or a quoted boolean
+func looseBool(field string, value any) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b, nil
+		}
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes":
+			return true, nil
+		case "no":
+			return false, nil
+		}
+	}
+	return false, &StructuredError{Field: field, Reason: fmt.Sprintf("unexpected value %v", value)}
+}
+
+// looseWeekday reads a weekday name, abbreviation or number, 0 and 7 being Sunday
+func looseWeekday(field string, value any) (string, error) {
+	if text, ok := value.(string); ok {
+		if n, ok := lookupName(weekdayNumbers, text); ok {
+			return weekdayNames[n], nil
+		}
+	}
+	n, err := looseInt(field, value, nil)
+	if err != nil || n < 0 || n > 7 {
+		return "", &StructuredError{Field: field, Reason: fmt.Sprintf("unknown weekday %v", value)}
+	}
+	return weekdayNames[n%7], nil
+}
+
+// looseWeekdays reads a list of weekdays
+func looseWeekdays(field string, value any) ([]string, error) {
+	var weekdays []string
+	for _, item := range looseList(value) {
+		weekday, err := looseWeekday(field, item)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(weekdays, weekday) {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	return weekdays, nil
+}
+
+// ordinals maps occurrence words to numbers
+var ordinals = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1,
+	"1st": 1, "2nd": 2, "3rd": 3, "4th": 4, "5th": 5,
+}
+
+// looseNth reads an nth weekday object with the occurrence as n, nth,
+// occurrence, week or ordinal, or a last flag
+func looseNth(value any) (*NthWeekday, error) {
+	if value == nil {
+		return nil, nil
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, &StructuredError{Field: "nth_weekday", Reason: fmt.Sprintf("unexpected value %v", value)}
+	}
+
+	nth := &NthWeekday{}
+	for key, v := range object {
+		var err error
+		switch normalizeKey(key) {
+		case "weekday", "day", "dayofweek":
+			nth.Weekday, err = looseWeekday("nth_weekday", v)
+		case "n", "nth", "occurrence", "week", "ordinal", "index":
+			if text, ok := v.(string); ok {
+				if n, ok := ordinals[strings.ToLower(strings.TrimSpace(text))]; ok {
+					nth.N = n
+					continue
+				}
+			}
+			nth.N, err = looseInt("nth_weekday", v, nil)
+		case "last":
+			var last bool
+			if last, err = looseBool("nth_weekday", v); last {
+				nth.N = -1
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nth, nil
+}
@@ -0,0 +1,360 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/flaticols/cronscribe/pkg/core/schedule"
+)
+
+func TestStructuredScheduleExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule StructuredSchedule
+		want     string
+	}{
+		{"every minute", StructuredSchedule{}, "* * * * *"},
+		{"every 15 minutes", StructuredSchedule{MinuteInterval: 15}, "*/15 * * * *"},
+		{"half past every hour", StructuredSchedule{Minutes: []int{30}}, "30 * * * *"},
+		{"daily", StructuredSchedule{Hours: []int{9}}, "0 9 * * *"},
+		{"every 2 hours", StructuredSchedule{HourInterval: 2}, "0 */2 * * *"},
+		{"weekdays", StructuredSchedule{Hours: []int{9}, Minutes: []int{30}, Weekdays: []string{"monday", "Friday"}}, "30 9 * * 1,5"},
+		{"midnight on sundays", StructuredSchedule{Weekdays: []string{"sunday"}}, "0 0 * * 0"},
+		{"every 10 minutes on mondays", StructuredSchedule{MinuteInterval: 10, Weekdays: []string{"monday"}}, "*/10 * * * 1"},
+		{"first and fifteenth", StructuredSchedule{Hours: []int{8}, Days: []int{1, 15}}, "0 8 1,15 * *"},
+		{"last day", StructuredSchedule{Hours: []int{23}, LastDayOfMonth: true}, "0 23 L * *"},
+		{"second tuesday", StructuredSchedule{Hours: []int{10}, NthWeekday: &NthWeekday{Weekday: "tuesday", N: 2}}, "0 10 * * 2#2"},
+		{"last friday", StructuredSchedule{Hours: []int{17}, NthWeekday: &NthWeekday{Weekday: "friday", N: -1}}, "0 17 * * 5L"},
+		{"quarterly", StructuredSchedule{Hours: []int{0}, Days: []int{1}, Months: []int{1, 4, 7, 10}}, "0 0 1 1,4,7,10 *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := tt.schedule.Expression()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("Expression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructuredScheduleInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule StructuredSchedule
+		field    string
+	}{
+		{"minute out of range", StructuredSchedule{Minutes: []int{60}}, "minutes"},
+		{"list and interval", StructuredSchedule{Hours: []int{9}, HourInterval: 2}, "hours"},
+		{"day zero", StructuredSchedule{Days: []int{0}}, "days"},
+		{"month 13", StructuredSchedule{Months: []int{13}}, "months"},
+		{"unknown weekday", StructuredSchedule{Weekdays: []string{"funday"}}, "weekdays"},
+		{"sixth occurrence", StructuredSchedule{NthWeekday: &NthWeekday{Weekday: "monday", N: 6}}, "nth_weekday"},
+		{"unknown zone", StructuredSchedule{Timezone: "Mars/Olympus"}, "timezone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.schedule.Expression()
+			var invalid *StructuredError
+			if !errors.As(err, &invalid) || invalid.Field != tt.field {
+				t.Errorf("Expression() error = %v, want a %s error", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestStructuredScheduleRender(t *testing.T) {
+	s := StructuredSchedule{Hours: []int{9}, Timezone: "Europe/Amsterdam"}
+
+	got, err := s.Render(cron.Standard.WithTimezonePrefix("TZ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "TZ=Europe/Amsterdam 0 9 * * *"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	if _, err := s.Render(cron.Dialect{Name: "plain"}); err == nil {
+		t.Error("Render() succeeded for a dialect without time zones")
+	}
+}
+
+func TestStructuredScheduleSchedule(t *testing.T) {
+	s := StructuredSchedule{Hours: []int{17}, NthWeekday: &NthWeekday{Weekday: "friday", N: -1}}
+
+	typed, err := s.Schedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := schedule.Field{{Kind: schedule.Last, From: 5}}
+	if !reflect.DeepEqual(typed.Weekdays, want) || !reflect.DeepEqual(typed.Hours, schedule.Values(17)) {
+		t.Errorf("Schedule() = %+v", typed)
+	}
+}
+
+func TestParseStructuredSchedule(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"schema", `{"hours": [9], "minutes": [0], "weekdays": ["monday"]}`, "0 9 * * 1"},
+		{"fenced with prose", "Sure! Here it is:\n```json\n{\"hours\": [18]}\n```\nLet me know.", "0 18 * * *"},
+		{"other spellings", `{"Hour": 7, "minute": "30", "dayOfWeek": "Mon,Wed,Fri"}`, "30 7 * * 1,3,5"},
+		{"weekday numbers", `{"hours": 6, "days_of_week": [0, 7, 6]}`, "0 6 * * 0,6"},
+		{"month names", `{"hours": 0, "day_of_month": 1, "months": ["jan", "July"]}`, "0 0 1 1,7 *"},
+		{"wildcards", `{"minute": "*/5", "hour": "*"}`, "*/5 * * * *"},
+		{"garbage value", `{"hours": ["noon"]}`, ""},
+		{"intervals", `{"everyMinutes": "20"}`, "*/20 * * * *"},
+		{"nth ordinal", `{"hours": [9], "nth_weekday": {"day": "thu", "occurrence": "third"}}`, "0 9 * * 4#3"},
+		{"nth last flag", `{"hours": [9], "nth": {"weekday": "friday", "last": true}}`, "0 9 * * 5L"},
+		{"last day string", `{"hours": [12], "last_day": "yes"}`, "0 12 L * *"},
+		{"arguments envelope", `{"name": "set_schedule", "arguments": "{\"hours\": [3]}"}`, "0 3 * * *"},
+		{"unknown keys", `{"hours": [4], "reasoning": "at four {am}"}`, "0 4 * * *"},
+		{"brace in prose", `The {schedule} is {"hours": [5]}`, "0 5 * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseStructuredSchedule(tt.text)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("ParseStructuredSchedule() = %+v, want an error", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expr, err := s.Expression()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("ParseStructuredSchedule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseStructuredSchedule("0 9 * * 1"); err == nil {
+		t.Error("ParseStructuredSchedule() accepted text without an object")
+	}
+}
+
+func TestParseStructuredScheduleKeyOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"step and null interval", `{"minutes": "*/15", "minute_interval": null, "hours": [9]}`, "*/15 9 * * *"},
+		{"step without hours", `{"minutes": "*/15", "minute_interval": null}`, "*/15 * * * *"},
+		{"zero interval", `{"minute_interval": 0, "minutes": "*/10", "hour_interval": 0, "hours": [6]}`, "*/10 6 * * *"},
+		{"null alias", `{"minute": null, "minutes": [30], "hours": [7]}`, "30 7 * * *"},
+		{"agreeing aliases", `{"dow": "mon", "weekdays": ["monday"], "hours": [8]}`, "0 8 * * 1"},
+		{"empty alias", `{"dow": [], "weekdays": ["friday"], "hours": [8], "last_day": false}`, "0 8 * * 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order varies between runs, so parse repeatedly
+			for range 50 {
+				s, err := ParseStructuredSchedule(tt.text)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expr, err := s.Expression()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := expr.String(); got != tt.want {
+					t.Fatalf("ParseStructuredSchedule() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+
+	conflicts := map[string]string{
+		`{"minute": [0], "minutes": [30]}`:                 "minutes",
+		`{"dow": "mon", "weekdays": ["friday"]}`:           "weekdays",
+		`{"minutes": "*/15", "minute_interval": 10}`:       "minute_interval",
+		`{"hours": "*/2", "every_hours": 3, "minutes": 0}`: "hour_interval",
+	}
+	for text, field := range conflicts {
+		_, err := ParseStructuredSchedule(text)
+		var invalid *StructuredError
+		if !errors.As(err, &invalid) || invalid.Field != field {
+			t.Errorf("ParseStructuredSchedule(%s) error = %v, want a %s conflict", text, err, field)
+		}
+	}
+}
+
+func TestStructuredScheduleSchema(t *testing.T) {
+	schema := StructuredScheduleSchema()
+	if schema["type"] != "object" || schema["additionalProperties"] != false {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+	if required := schema["required"].([]string); len(required) != 0 {
+		t.Errorf("required = %v, want none", required)
+	}
+
+	properties := schema["properties"].(map[string]any)
+	minutes := properties["minutes"].(map[string]any)
+	items := minutes["items"].(map[string]any)
+	if minutes["type"] != "array" || items["type"] != "integer" || items["minimum"] != 0 || items["maximum"] != 59 {
+		t.Errorf("unexpected minutes schema %v", minutes)
+	}
+
+	weekdays := properties["weekdays"].(map[string]any)["items"].(map[string]any)
+	if len(weekdays["enum"].([]string)) != 7 {
+		t.Errorf("unexpected weekdays schema %v", weekdays)
+	}
+
+	nth := properties["nth_weekday"].(map[string]any)
+	if !reflect.DeepEqual(nth["required"], []string{"weekday", "n"}) {
+		t.Errorf("nth_weekday required = %v", nth["required"])
+	}
+
+	if _, err := JSONSchema(struct{ C chan int }{}); err == nil {
+		t.Error("JSONSchema() accepted a channel")
+	}
+}
+
+func TestOpenAIProviderStructured(t *testing.T) {
+	var body map[string]any
+	server := stubServer(t, "/chat/completions", http.StatusOK, `{
+		"model": "gpt-test",
+		"choices": [{"message": {"role": "assistant", "content": "", "tool_calls": [
+			{"type": "function", "function": {"name": "set_schedule", "arguments": "{\"hours\":[9],\"weekdays\":[\"monday\"]}"}}
+		]}}]
+	}`, &body, nil)
+
+	provider := NewOpenAIProvider(WithBaseURL(server.URL), WithStructuredOutput())
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.GenerateSchedule(context.Background(), ScheduleRequest{
+		Input:    "mondays at nine",
+		Dialect:  cron.Standard.WithTimezonePrefix("TZ"),
+		Location: amsterdam,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "TZ=Europe/Amsterdam 0 9 * * 1"; resp.Expression != want {
+		t.Errorf("Expression = %q, want %q", resp.Expression, want)
+	}
+	if resp.Structured == nil || !reflect.DeepEqual(resp.Structured.Weekdays, []string{"monday"}) {
+		t.Errorf("Structured = %+v", resp.Structured)
+	}
+
+	tools := body["tools"].([]any)
+	function := tools[0].(map[string]any)["function"].(map[string]any)
+	if function["name"] != StructuredToolName || function["parameters"].(map[string]any)["type"] != "object" {
+		t.Errorf("unexpected tools %v", tools)
+	}
+	if body["tool_choice"] == nil {
+		t.Error("tool_choice was not sent")
+	}
+}
+
+func TestOllamaProviderStructured(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"format", `{"message": {"role": "assistant", "content": "{\"minute_interval\": 5}"}}`, "*/5 * * * *"},
+		{"tool call object", `{"message": {"role": "assistant", "content": "", "tool_calls": [
+			{"function": {"name": "set_schedule", "arguments": {"hours": [6], "last_day_of_month": true}}}
+		]}}`, "0 6 L * *"},
+		{"cron text", `{"message": {"role": "assistant", "content": "Use 0 7 * * * for that."}}`, "0 7 * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			server := stubServer(t, "/api/chat", http.StatusOK, tt.response, &body, nil)
+			provider := NewOllamaProvider(WithBaseURL(server.URL), WithStructuredOutput())
+
+			resp, err := provider.GenerateSchedule(context.Background(), ScheduleRequest{Input: "sometime"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Expression != tt.want {
+				t.Errorf("Expression = %q, want %q", resp.Expression, tt.want)
+			}
+			if body["format"].(map[string]any)["type"] != "object" {
+				t.Errorf("format = %v", body["format"])
+			}
+		})
+	}
+}
+
+func TestStructuredResponseErrors(t *testing.T) {
+	server := stubServer(t, "/api/chat", http.StatusOK, `{"message": {"content": "{\"hours\": [25]}"}}`, nil, nil)
+	provider := NewOllamaProvider(WithBaseURL(server.URL), WithStructuredOutput())
+
+	_, err := provider.GenerateSchedule(context.Background(), ScheduleRequest{Input: "sometime"})
+	var invalid *StructuredError
+	if !errors.As(err, &invalid) || invalid.Field != "hours" {
+		t.Errorf("error = %v, want an hours error", err)
+	}
+
+	server = stubServer(t, "/api/chat", http.StatusOK, `{"message": {"content": "I cannot help with that."}}`, nil, nil)
+	provider = NewOllamaProvider(WithBaseURL(server.URL), WithStructuredOutput())
+
+	_, err = provider.GenerateSchedule(context.Background(), ScheduleRequest{Input: "sometime"})
+	var extraction *ExtractionError
+	if !errors.As(err, &extraction) {
+		t.Errorf("error = %v, want an ExtractionError", err)
+	}
+}
+
+func TestStructuredOutputThroughMapper(t *testing.T) {
+	server := stubServer(t, "/chat/completions", http.StatusOK, `{
+		"model": "gpt-test",
+		"choices": [{"message": {"role": "assistant", "content": "", "tool_calls": [
+			{"type": "function", "function": {"name": "set_schedule", "arguments": "{\"hours\":[9],\"weekdays\":[\"monday\"]}"}}
+		]}}]
+	}`, nil, nil)
+
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := core.New("../core/rules", core.WithDialect(cron.StandardSeconds.WithTimezonePrefix("TZ")), core.WithLocation(amsterdam))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewOpenAIProvider(WithBaseURL(server.URL), WithStructuredOutput())
+	mapper, err := WithCore(cs, nil, WithScheduleProvider(provider), WithInputGuard(WithStrictOutput(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mapper.ToCronResult(context.Background(), "mondays at nine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "TZ=Europe/Amsterdam 0 0 9 * * 1"; result.Expression != want || result.Source != SourceAI {
+		t.Errorf("result = %q from %s, want %q", result.Expression, result.Source, want)
+	}
+	if result.AI == nil || result.AI.Structured == nil {
+		t.Error("structured schedule was dropped")
+	}
+}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeoutStats holds the counters of a TimeoutProvider
+type TimeoutStats struct {
+	// Calls is the number of requests received
+	Calls int64 `json:"calls"`
+	// Timeouts is the number of requests cut off by the timeout
+	Timeouts int64 `json:"timeouts"`
+}
+
+// TimeoutProvider limits how long a single request to the wrapped provider may take
+type TimeoutProvider struct {
+	provider ScheduleProvider
+	timeout  time.Duration
+
+	mu    sync.Mutex
+	stats TimeoutStats
+}
+
+// NewTimeoutProvider wraps a provider with a per-request timeout. Wrapped by
+// a RetryProvider, the timeout applies to every attempt.
+func NewTimeoutProvider(provider ScheduleProvider, timeout time.Duration) *TimeoutProvider {
+	return &TimeoutProvider{provider: provider, timeout: timeout}
+}
+
+// GenerateSchedule implements the ScheduleProvider interface
+func (t *TimeoutProvider) GenerateSchedule(ctx context.Context, req ScheduleRequest) (*ScheduleResponse, error) {
+	t.mu.Lock()
+	t.stats.Calls++
+	t.mu.Unlock()
+
+	attemptCtx, cancel := context.WithTimeoutCause(ctx, t.timeout, fmt.Errorf("AI request exceeded %s: %w", t.timeout, context.DeadlineExceeded))
+	defer cancel()
+
+	resp, err := t.provider.GenerateSchedule(attemptCtx, req)
+	if err != nil && ctx.Err() == nil && attemptCtx.Err() != nil {
+		t.mu.Lock()
+		t.stats.Timeouts++
+		t.mu.Unlock()
+		return nil, context.Cause(attemptCtx)
+	}
+	return resp, err
+}
+
+// GenerateCron implements the AIProvider interface
+func (t *TimeoutProvider) GenerateCron(ctx context.Context, input string) (string, error) {
+	return generateCron(ctx, t, input)
+}
+
+// Stats returns a snapshot of the timeout counters
+func (t *TimeoutProvider) Stats() TimeoutStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
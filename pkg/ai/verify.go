@@ -0,0 +1,314 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+// VerifyOption represents a functional option for configuring answer verification
+type VerifyOption func(*verifier)
+
+// WithSamples sets how many answers are requested from the provider, 5 by default
+func WithSamples(samples int) VerifyOption {
+	return func(v *verifier) {
+		if samples > 0 {
+			v.samples = samples
+		}
+	}
+}
+
+// WithQuorum sets the share of valid answers that must agree, more than 0.5 by default
+func WithQuorum(quorum float64) VerifyOption {
+	return func(v *verifier) {
+		v.quorum = quorum
+	}
+}
+
+// WithCrossCheck checks the answer against the rules engine on every part of
+// the input the rules can convert, comparing only the fields that part sets,
+// such as the time of day. Rules match anywhere in the input, so this mostly
+// pays off with WithAIFirst, where the AI also answers inputs the rules cover.
+func WithCrossCheck(enabled bool) VerifyOption {
+	return func(v *verifier) {
+		v.crossCheck = enabled
+	}
+}
+
+// WithAcceptUnverified returns answers that fail verification as results
+// flagged with Verification.Confident set to false, instead of a VerificationError
+func WithAcceptUnverified(accept bool) VerifyOption {
+	return func(v *verifier) {
+		v.acceptUnverified = accept
+	}
+}
+
+// WithVerification samples the provider several times and only trusts an
+// answer most samples agree on, comparing schedules rather than strings. The
+// samples are requested concurrently, so the provider must be safe for
+// concurrent use.
+func WithVerification(options ...VerifyOption) BraveOption {
+	return func(m *BraveHumanCronMapper) {
+		v := &verifier{samples: 5, quorum: 0.5}
+		for _, option := range options {
+			option(v)
+		}
+		m.verifier = v
+	}
+}
+
+// verifier holds the verification settings
+type verifier struct {
+	samples          int
+	quorum           float64
+	crossCheck       bool
+	acceptUnverified bool
+}
+
+// Verification reports how an AI answer was verified
+type Verification struct {
+	// Samples is the number of answers requested from the provider
+	Samples int `json:"samples"`
+	// Valid is the number of answers that held a valid expression
+	Valid int `json:"valid"`
+	// Votes is the number of valid answers equivalent to the chosen one
+	Votes int `json:"votes"`
+	// Agreement is Votes divided by Valid
+	Agreement float64 `json:"agreement"`
+	// Alternatives are the other schedules the provider answered, one per group of equivalent answers
+	Alternatives []string `json:"alternatives,omitempty"`
+	// CrossChecks are the comparisons with the rules engine
+	CrossChecks []CrossCheck `json:"cross_checks,omitempty"`
+	// Confident is false when the answers disagree or a cross-check failed
+	Confident bool `json:"confident"`
+}
+
+// CrossCheck compares the AI answer with the rules engine on part of the input
+type CrossCheck struct {
+	// Phrase is the part of the input the rules converted
+	Phrase string `json:"phrase"`
+	// Expression is the rules engine's expression for the phrase
+	Expression string `json:"expression"`
+	// Fields are the fields the phrase determines, which are compared
+	Fields []string `json:"fields"`
+	// Agrees reports whether the AI answer has the same values in these fields
+	Agrees bool `json:"agrees"`
+}
+
+// VerificationError is returned when the provider's answers cannot be trusted
+type VerificationError struct {
+	// Result is the best answer, flagged with the verification details
+	Result *Result
+}
+
+func (e *VerificationError) Error() string {
+	v := e.Result.Verification
+	for _, check := range v.CrossChecks {
+		if !check.Agrees {
+			return fmt.Sprintf("AI answer %s contradicts %q, which the rules convert to %s", e.Result.Expression, check.Phrase, check.Expression)
+		}
+	}
+	return fmt.Sprintf("AI answer %s not verified: %d of %d valid answers agree, alternatives %q", e.Result.Expression, v.Votes, v.Valid, v.Alternatives)
+}
+
+// sample is one answer of the provider
+type sample struct {
+	response *ScheduleResponse
+	schedule *cron.Expression
+	err      error
+}
+
+// generateVerified samples the provider, groups the answers by equivalence and
+// checks the largest group
+func (m *BraveHumanCronMapper) generateVerified(ctx context.Context, req ScheduleRequest) (*Result, error) {
+	samples := make([]sample, m.verifier.samples)
+
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			samples[i] = m.sample(ctx, req)
+		}()
+	}
+	wg.Wait()
+
+	// Group equivalent answers, keeping the order of the first answer of every group
+	var groups [][]sample
+	var usage Usage
+	var lastErr error
+	for _, s := range samples {
+		if s.response != nil {
+			usage.PromptTokens += s.response.Usage.PromptTokens
+			usage.CompletionTokens += s.response.Usage.CompletionTokens
+			usage.TotalTokens += s.response.Usage.TotalTokens
+		}
+		if s.err != nil {
+			lastErr = s.err
+			continue
+		}
+
+		grouped := false
+		for i, group := range groups {
+			if group[0].schedule.Equivalent(s.schedule) {
+				groups[i] = append(group, s)
+				grouped = true
+				break
+			}
+		}
+		if !grouped {
+			groups = append(groups, []sample{s})
+		}
+	}
+
+	if len(groups) == 0 {
+		return nil, lastErr
+	}
+
+	best := 0
+	for i, group := range groups {
+		if len(group) > len(groups[best]) {
+			best = i
+		}
+	}
+
+	valid := 0
+	for _, group := range groups {
+		valid += len(group)
+	}
+
+	response := groups[best][0].response
+	response.Usage = usage
+
+	verification := &Verification{
+		Samples:   len(samples),
+		Valid:     valid,
+		Votes:     len(groups[best]),
+		Agreement: float64(len(groups[best])) / float64(valid),
+	}
+	for i, group := range groups {
+		if i != best {
+			verification.Alternatives = append(verification.Alternatives, group[0].response.Expression)
+		}
+	}
+
+	tied := false
+	for i, group := range groups {
+		if i != best && len(group) == len(groups[best]) {
+			tied = true
+		}
+	}
+	verification.Confident = !tied && verification.Agreement > m.verifier.quorum
+
+	if m.verifier.crossCheck {
+		verification.CrossChecks = m.crossCheck(req, groups[best][0].schedule)
+		for _, check := range verification.CrossChecks {
+			if !check.Agrees {
+				verification.Confident = false
+			}
+		}
+	}
+
+	result := &Result{Expression: response.Expression, Source: SourceAI, AI: response, Verification: verification}
+	if !verification.Confident && !m.verifier.acceptUnverified {
+		return nil, &VerificationError{Result: result}
+	}
+	return result, nil
+}
+
+// sample asks the provider once and parses the extracted expression
+func (m *BraveHumanCronMapper) sample(ctx context.Context, req ScheduleRequest) sample {
+	response, err := m.provider.GenerateSchedule(ctx, req)
+	if err != nil {
+		return sample{err: err}
+	}
+
+	expression, err := m.output(response, req)
+	if err != nil {
+		return sample{response: response, err: err}
+	}
+	response.Expression = expression
+
+	schedule, err := cron.Parse(expression)
+	if err != nil {
+		return sample{response: response, err: err}
+	}
+	return sample{response: response, schedule: schedule}
+}
+
+// crossCheck converts every part of the input the rules engine understands and
+// compares the fields the part determines with the AI answer. Longer parts are
+// tried first, and parts inside an already converted part are skipped.
+func (m *BraveHumanCronMapper) crossCheck(req ScheduleRequest, answer *cron.Expression) []CrossCheck {
+	words := strings.Fields(req.Input)
+	covered := make([]bool, len(words))
+
+	var checks []CrossCheck
+	for size := len(words); size > 0; size-- {
+		for start := 0; start+size <= len(words); start++ {
+			if allCovered(covered[start : start+size]) {
+				continue
+			}
+
+			phrase := strings.Join(words[start:start+size], " ")
+			check, ok := m.checkPhrase(req, phrase, answer)
+			if !ok {
+				continue
+			}
+
+			checks = append(checks, check)
+			for i := start; i < start+size; i++ {
+				covered[i] = true
+			}
+		}
+	}
+	return checks
+}
+
+func allCovered(covered []bool) bool {
+	for _, c := range covered {
+		if !c {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPhrase compares the AI answer with the rules engine's expression for a phrase
+func (m *BraveHumanCronMapper) checkPhrase(req ScheduleRequest, phrase string, answer *cron.Expression) (CrossCheck, bool) {
+	convert := m.coreMapper.ConvertResult
+	if req.Language == "" {
+		convert = m.coreMapper.AutoDetectResult
+	}
+
+	result, err := convert(phrase)
+	if err != nil {
+		return CrossCheck{}, false
+	}
+	expected, err := cron.Parse(result.Expression)
+	if err != nil {
+		return CrossCheck{}, false
+	}
+
+	// Compare only the fields the phrase restricts
+	a := &cron.Expression{Fields: [5]string{"*", "*", "*", "*", "*"}}
+	b := &cron.Expression{Fields: a.Fields}
+	check := CrossCheck{Phrase: phrase, Expression: result.Expression}
+	for f := cron.Minute; f <= cron.DayOfWeek; f++ {
+		if expected.Field(f) == "*" {
+			continue
+		}
+		a.Fields[f] = expected.Field(f)
+		b.Fields[f] = answer.Field(f)
+		check.Fields = append(check.Fields, f.String())
+	}
+	if len(check.Fields) == 0 {
+		return CrossCheck{}, false
+	}
+
+	check.Agrees = a.Equivalent(b)
+	return check, true
+}
@@ -0,0 +1,126 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// answersProvider hands out its answers in turn, safe for concurrent use
+type answersProvider struct {
+	mu      sync.Mutex
+	answers []string
+	calls   int
+}
+
+func (p *answersProvider) GenerateSchedule(_ context.Context, _ ScheduleRequest) (*ScheduleResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	answer := p.answers[p.calls%len(p.answers)]
+	p.calls++
+	return &ScheduleResponse{Expression: answer, Usage: Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}}, nil
+}
+
+func TestVerificationMajority(t *testing.T) {
+	provider := &answersProvider{answers: []string{"0 9 * * 1-5", "0 9 * * mon-fri", "`0 9 * * MON-FRI`", "0 21 * * 1-5", "no idea"}}
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil, WithScheduleProvider(provider), WithVerification())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mapper.ToCronResult(context.Background(), "business mornings")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := result.Verification
+	if provider.calls != 5 || v.Samples != 5 || v.Valid != 4 || v.Votes != 3 || !v.Confident {
+		t.Errorf("unexpected verification %+v", v)
+	}
+	if len(v.Alternatives) != 1 || v.Alternatives[0] != "0 21 * * 1-5" {
+		t.Errorf("unexpected alternatives %q", v.Alternatives)
+	}
+	if !strings.HasPrefix(result.Expression, "0 9 * * ") {
+		t.Errorf("unexpected expression %q", result.Expression)
+	}
+	if result.AI.Usage.TotalTokens != 60 {
+		t.Errorf("usage of all samples not summed: %+v", result.AI.Usage)
+	}
+}
+
+func TestVerificationDisagreement(t *testing.T) {
+	answers := []string{"0 9 * * *", "0 10 * * *", "0 11 * * *"}
+
+	mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(&answersProvider{answers: answers}),
+		WithVerification(WithSamples(3)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = mapper.ToCron("some time in the morning")
+	var verificationErr *VerificationError
+	if !errors.As(err, &verificationErr) {
+		t.Fatalf("expected VerificationError, got %v", err)
+	}
+	if v := verificationErr.Result.Verification; v.Confident || v.Votes != 1 || len(v.Alternatives) != 2 {
+		t.Errorf("unexpected verification %+v", v)
+	}
+
+	mapper, err = NewBraveHumanCronMapper("../core/rules", nil,
+		WithScheduleProvider(&answersProvider{answers: answers}),
+		WithVerification(WithSamples(3), WithAcceptUnverified(true)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := mapper.ToCronResult(context.Background(), "some time in the morning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Verification.Confident {
+		t.Error("disagreeing answers flagged as confident")
+	}
+}
+
+func TestVerificationCrossCheck(t *testing.T) {
+	tests := []struct {
+		answer string
+		agrees bool
+	}{
+		{"30 9 * * 1-5", true},
+		{"30 21 * * 1-5", false},
+	}
+
+	for _, tt := range tests {
+		mapper, err := NewBraveHumanCronMapper("../core/rules", nil,
+			WithScheduleProvider(&answersProvider{answers: []string{tt.answer}}),
+			WithAIFirst(true),
+			WithVerification(WithSamples(2), WithCrossCheck(true), WithAcceptUnverified(true)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := mapper.ToCronResult(context.Background(), "on workdays, every day at 9:30")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checks := result.Verification.CrossChecks
+		if len(checks) != 1 || checks[0].Phrase != "on workdays, every day at 9:30" || checks[0].Agrees != tt.agrees {
+			t.Fatalf("%s: unexpected cross-checks %+v", tt.answer, checks)
+		}
+		if strings.Join(checks[0].Fields, ",") != "minute,hour" {
+			t.Errorf("unexpected fields %q", checks[0].Fields)
+		}
+		if result.Verification.Confident != tt.agrees {
+			t.Errorf("%s: confident = %v", tt.answer, result.Verification.Confident)
+		}
+	}
+}
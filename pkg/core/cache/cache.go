@@ -0,0 +1,147 @@
+// Package cache stores conversion results so repeated inputs skip rule
+// matching and AI calls
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Store is a backend for cached entries. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value stored under the key, or false when it is missing or expired
+	Get(key string) ([]byte, bool)
+	// Set stores a value under the key for the given time to live
+	Set(key string, value []byte, ttl time.Duration) error
+	// Clear removes all entries
+	Clear() error
+}
+
+// DefaultTTL is how long successful conversions are cached by default
+const DefaultTTL = 24 * time.Hour
+
+// DefaultNegativeTTL is how long failed conversions are cached by default
+const DefaultNegativeTTL = 5 * time.Minute
+
+// Option represents a functional option for configuring Cache
+type Option func(*Cache)
+
+// WithTTL sets how long successful conversions are cached
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithNegativeTTL sets how long failed conversions are cached, 0 disables negative caching
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// Cache encodes conversion outcomes into a Store
+type Cache struct {
+	store       Store
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// New creates a cache on top of a store
+func New(store Store, options ...Option) *Cache {
+	c := &Cache{
+		store:       store,
+		ttl:         DefaultTTL,
+		negativeTTL: DefaultNegativeTTL,
+	}
+
+	// Apply all options
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// Entry is an outcome read from the cache
+type Entry struct {
+	// Failure reports whether the entry records a failed conversion
+	Failure bool            `json:"failure,omitempty"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// Decode decodes the cached value
+func (e Entry) Decode(value any) error {
+	return json.Unmarshal(e.Value, value)
+}
+
+// Key builds a cache key from its parts, such as the normalized input, the
+// language, the dialect and the rule set version
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the entry stored under the key
+func (c *Cache) Get(key string) (Entry, bool) {
+	data, ok := c.store.Get(key)
+	if !ok {
+		return Entry{}, false
+	}
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Put stores a successful outcome
+func (c *Cache) Put(key string, value any) error {
+	return c.put(key, value, false, c.ttl)
+}
+
+// PutFailure stores the details of a failed conversion, if negative caching is enabled
+func (c *Cache) PutFailure(key string, value any) error {
+	if c.negativeTTL <= 0 {
+		return nil
+	}
+	return c.put(key, value, true, c.negativeTTL)
+}
+
+// Clear removes all entries from the store
+func (c *Cache) Clear() error {
+	return c.store.Clear()
+}
+
+func (c *Cache) put(key string, value any, failure bool, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	data, err = json.Marshal(Entry{Failure: failure, Value: data})
+	if err != nil {
+		return err
+	}
+	return c.store.Set(key, data, ttl)
+}
+
+// Error is a failure served from the cache. It keeps the message of the
+// original error and unwraps to the error rebuilt from the cached details.
+type Error struct {
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+func TestMemory(t *testing.T) {
+	c := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := NewMemory(2)
+	m.now = c.Now
+
+	m.Set("a", []byte("1"), time.Minute)
+	m.Set("b", []byte("2"), time.Hour)
+	m.Get("a")
+	m.Set("c", []byte("3"), time.Hour)
+
+	if _, ok := m.Get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if v, ok := m.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("Get(a) = %q, %v", v, ok)
+	}
+
+	c.now = c.now.Add(time.Minute)
+	if _, ok := m.Get("a"); ok {
+		t.Error("expired entry was returned")
+	}
+	if m.Len() != 1 {
+		t.Errorf("Len = %d, want 1", m.Len())
+	}
+
+	m.Clear()
+	if _, ok := m.Get("c"); ok {
+		t.Error("entry survived Clear")
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	f, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.now = c.Now
+
+	key := Key("every monday", "en")
+	if err := f.Set(key, []byte("payload"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second store on the same directory sees the entry
+	other, _ := NewFileStore(dir)
+	other.now = c.Now
+	if v, ok := other.Get(key); !ok || string(v) != "payload" {
+		t.Fatalf("Get = %q, %v", v, ok)
+	}
+
+	c.now = c.now.Add(time.Hour)
+	if _, ok := f.Get(key); ok {
+		t.Error("expired entry was returned")
+	}
+
+	f.Set("../escape", []byte("x"), time.Hour)
+	if err := f.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.Get("../escape"); ok {
+		t.Error("entry survived Clear")
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := New(NewMemory(10), WithNegativeTTL(0))
+
+	if err := c.Put("ok", map[string]string{"expression": "0 9 * * 1"}); err != nil {
+		t.Fatal(err)
+	}
+	c.PutFailure("failed", "no rule")
+
+	entry, ok := c.Get("ok")
+	if !ok || entry.Failure {
+		t.Fatalf("Get(ok) = %+v, %v", entry, ok)
+	}
+	var value map[string]string
+	if err := entry.Decode(&value); err != nil || value["expression"] != "0 9 * * 1" {
+		t.Errorf("Decode = %v, %v", value, err)
+	}
+
+	if _, ok := c.Get("failed"); ok {
+		t.Error("failure cached with negative caching disabled")
+	}
+
+	c = New(NewMemory(10))
+	c.PutFailure("failed", "no rule")
+	if entry, ok := c.Get("failed"); !ok || !entry.Failure {
+		t.Errorf("Get(failed) = %+v, %v", entry, ok)
+	}
+
+	if Key("a", "bc") == Key("ab", "c") {
+		t.Error("key parts are not separated")
+	}
+}
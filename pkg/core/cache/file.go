@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileStore keeps every entry in its own file, so cached conversions survive
+// restarts and can be shared by processes on the same machine
+type FileStore struct {
+	dir string
+	now func() time.Time
+}
+
+type fileEntry struct {
+	Expires time.Time `json:"expires"`
+	Value   []byte    `json:"value"`
+}
+
+// NewFileStore creates a store in the given directory, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &FileStore{dir: dir, now: time.Now}, nil
+}
+
+// Get implements the Store interface
+func (f *FileStore) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var e fileEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	if !f.now().Before(e.Expires) {
+		os.Remove(f.path(key))
+		return nil, false
+	}
+	return e.Value, true
+}
+
+// Set implements the Store interface. The entry is written to a temporary
+// file first, so readers never see a partial entry.
+func (f *FileStore) Set(key string, value []byte, ttl time.Duration) error {
+	data, err := json.Marshal(fileEntry{Expires: f.now().Add(ttl), Value: value})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(f.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear implements the Store interface
+func (f *FileStore) Clear() error {
+	files, err := filepath.Glob(filepath.Join(f.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// path returns the file of a key. Keys built with Key are hex digests; other
+// keys are stripped of path separators.
+func (f *FileStore) path(key string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(key)
+	return filepath.Join(f.dir, name+".json")
+}
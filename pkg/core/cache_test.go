@@ -0,0 +1,154 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cache"
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// countingStore counts the hits of a store
+type countingStore struct {
+	cache.Store
+	hits int
+}
+
+func (s *countingStore) Get(key string) ([]byte, bool) {
+	value, ok := s.Store.Get(key)
+	if ok {
+		s.hits++
+	}
+	return value, ok
+}
+
+func TestCache(t *testing.T) {
+	store := &countingStore{Store: cache.NewMemory(100)}
+	c, err := New("rules", WithCache(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := c.ConvertResult("every day at 9:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.ConvertResult("  Every Day at 9:30 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.hits != 1 {
+		t.Fatalf("expected a cache hit, got %d", store.hits)
+	}
+	if second.Expression != first.Expression || second.Rule != first.Rule || second.Language != "en" {
+		t.Errorf("cached result %+v differs from %+v", second, first)
+	}
+
+	after := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if !second.Next(after).Equal(first.Next(after)) {
+		t.Errorf("cached schedule fires at %v, want %v", second.Next(after), first.Next(after))
+	}
+
+	// Failures are cached and keep their meaning
+	for range 2 {
+		_, err := c.Convert("whenever the moon is full")
+		if !errors.Is(err, rules.ErrNoMatch) {
+			t.Fatalf("expected ErrNoMatch, got %v", err)
+		}
+	}
+	if store.hits != 2 {
+		t.Errorf("expected the failure to be cached, got %d hits", store.hits)
+	}
+
+	// The language is part of the key
+	if err := c.SetLanguage("nl"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Convert("every day at 9:30"); err == nil {
+		t.Error("English input converted with Dutch rules")
+	}
+	c.SetLanguage("en")
+
+	// New rules invalidate cached conversions
+	version := c.RulesVersion()
+	moon, err := rules.NewRules("en").Add(
+		rules.NewRule("full_moon").Pattern(`^whenever the moon is full$`).Format("0 0 1 * *"),
+	).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddRules(moon); err != nil {
+		t.Fatal(err)
+	}
+	if c.RulesVersion() == version {
+		t.Fatal("rules version did not change")
+	}
+	if expr, err := c.Convert("whenever the moon is full"); err != nil || expr != "0 0 1 * *" {
+		t.Errorf("Convert = %q, %v", expr, err)
+	}
+
+	if err := c.ReloadRules(); err != nil {
+		t.Fatal(err)
+	}
+	if c.RulesVersion() != version {
+		t.Error("reloading the rules directory did not restore the version")
+	}
+	if _, err := c.Convert("whenever the moon is full"); !errors.Is(err, rules.ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch after reload, got %v", err)
+	}
+}
+
+func TestCacheDate(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New("rules", WithCache(cache.NewMemory(100)), WithLocation(amsterdam))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The DST warnings name the first transition after the conversion date
+	for _, tt := range []struct {
+		now  time.Time
+		want string
+	}{
+		{time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC), "first 2025-03-30 02:30"},
+		{time.Date(2025, time.January, 15, 18, 0, 0, 0, time.UTC), "first 2025-03-30 02:30"},
+		{time.Date(2025, time.April, 1, 12, 0, 0, 0, time.UTC), "first 2026-03-29 02:30"},
+	} {
+		c.now = func() time.Time { return tt.now }
+		result, err := c.ConvertResult("every day at 2:30am")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Warnings) == 0 || result.Warnings[0].Code != WarningDSTGap || !strings.Contains(result.Warnings[0].Message, tt.want) {
+			t.Errorf("on %s Warnings = %+v, want %q", tt.now, result.Warnings, tt.want)
+		}
+	}
+}
+
+func TestCacheFileStore(t *testing.T) {
+	store, err := cache.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	for range 2 {
+		c, err := New("rules", WithCache(store), WithLocation(amsterdam))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := c.ConvertResult("every day at 2:30 Europe/Amsterdam")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Location.String() != "Europe/Amsterdam" || len(result.Warnings) == 0 {
+			t.Errorf("unexpected result %+v", result)
+		}
+	}
+}
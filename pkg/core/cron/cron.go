@@ -0,0 +1,385 @@
+// Package cron parses, validates and renders cron expressions
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field identifies a field of a cron expression
+type Field int
+
+const (
+	Minute Field = iota
+	Hour
+	DayOfMonth
+	Month
+	DayOfWeek
+	// Second and Year are the optional first and last fields of 6- and
+	// 7-field expressions
+	Second
+	Year
+)
+
+// fieldSpec describes the allowed values of a field
+type fieldSpec struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var specs = [...]fieldSpec{
+	Minute:     {name: "minute", min: 0, max: 59},
+	Hour:       {name: "hour", min: 0, max: 23},
+	DayOfMonth: {name: "day of month", min: 1, max: 31},
+	Month: {name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	DayOfWeek: {name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+	Second: {name: "second", min: 0, max: 59},
+	Year:   {name: "year", min: minYear, max: maxYear},
+}
+
+// minYear and maxYear bound the year field, as in Quartz
+const (
+	minYear = 1970
+	maxYear = 2099
+)
+
+// String returns the name of the field
+func (f Field) String() string {
+	return specs[f].name
+}
+
+// Expression is a parsed and normalized cron expression
+type Expression struct {
+	// Fields holds the normalized minute, hour, day of month, month and day of week fields
+	Fields [5]string
+	// Second holds the normalized seconds field, empty for expressions firing at second 0
+	Second string
+	// Year holds the normalized year field, empty for expressions firing every year
+	Year string
+	// Location is the time zone the expression is evaluated in, nil when unspecified
+	Location *time.Location
+}
+
+// ParseError is returned for malformed cron expressions
+type ParseError struct {
+	Expression string
+	Field      string
+	Reason     string
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid cron expression %q: %s", e.Expression, e.Reason)
+	}
+	return fmt.Sprintf("invalid cron expression %q: %s field: %s", e.Expression, e.Field, e.Reason)
+}
+
+var timezonePrefix = regexp.MustCompile(`^(?:CRON_TZ|TZ)=(\S+)\s+`)
+
+// Macros maps the cron macros to the expressions they stand for. @reboot and
+// @every are not calendar schedules and are read by ParseInterval and IsReboot.
+var Macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Reboot is the macro of jobs that run once when the scheduler starts
+const Reboot = "@reboot"
+
+// Parse parses a cron expression, optionally prefixed with CRON_TZ= or TZ=.
+// It accepts 5 fields, 6 with seconds first, 7 with seconds first and years
+// last, and the macros of Macros. Month and weekday names are replaced with
+// numbers, Sunday is always 0, and seconds of 0 and years of * are dropped,
+// so "0 0 9 * * *" reads the same as "0 9 * * *".
+func Parse(expr string) (*Expression, error) {
+	text := strings.TrimSpace(expr)
+	e := &Expression{}
+
+	if m := timezonePrefix.FindStringSubmatch(text); m != nil {
+		loc, err := time.LoadLocation(m[1])
+		if err != nil {
+			return nil, &ParseError{Expression: expr, Reason: fmt.Sprintf("unknown time zone %s", m[1])}
+		}
+		e.Location = loc
+		text = text[len(m[0]):]
+	}
+
+	if strings.HasPrefix(text, "@") {
+		macro, ok := Macros[strings.ToLower(text)]
+		if !ok {
+			return nil, &ParseError{Expression: expr, Reason: fmt.Sprintf("%s is not a calendar schedule", text)}
+		}
+		text = macro
+	}
+
+	fields := strings.Fields(text)
+	var second, year string
+	switch len(fields) {
+	case 5:
+	case 6:
+		second, fields = fields[0], fields[1:]
+	case 7:
+		second, year, fields = fields[0], fields[6], fields[1:6]
+	default:
+		return nil, &ParseError{Expression: expr, Reason: fmt.Sprintf("expected 5 to 7 fields, got %d", len(fields))}
+	}
+
+	if second != "" {
+		normalized, err := parseField(Second, second)
+		if err != nil {
+			return nil, &ParseError{Expression: expr, Field: Second.String(), Reason: err.Error()}
+		}
+		if normalized != "0" {
+			e.Second = normalized
+		}
+	}
+	if year != "" {
+		normalized, err := parseField(Year, year)
+		if err != nil {
+			return nil, &ParseError{Expression: expr, Field: Year.String(), Reason: err.Error()}
+		}
+		if normalized != "*" {
+			e.Year = normalized
+		}
+	}
+
+	for i, value := range fields {
+		normalized, err := parseField(Field(i), value)
+		if err != nil {
+			return nil, &ParseError{Expression: expr, Field: Field(i).String(), Reason: err.Error()}
+		}
+		e.Fields[i] = normalized
+	}
+
+	return e, nil
+}
+
+// ParseInterval parses an "@every <duration>" expression, such as @every 1h30m,
+// and returns its interval
+func ParseInterval(expr string) (time.Duration, error) {
+	text := strings.TrimSpace(expr)
+	macro, duration, _ := strings.Cut(text, " ")
+	if !strings.EqualFold(macro, "@every") {
+		return 0, &ParseError{Expression: expr, Reason: "expected @every <duration>"}
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(duration))
+	if err != nil {
+		return 0, &ParseError{Expression: expr, Reason: fmt.Sprintf("invalid duration %q", strings.TrimSpace(duration))}
+	}
+	if d < time.Second {
+		return 0, &ParseError{Expression: expr, Reason: fmt.Sprintf("interval %s is shorter than a second", d)}
+	}
+	return d, nil
+}
+
+// FormatInterval writes an interval as an "@every <duration>" expression with
+// the duration in its shortest form, leaving out zero units: 90 minutes is
+// written as @every 1h30m, not 1h30m0s
+func FormatInterval(d time.Duration) string {
+	var b strings.Builder
+	b.WriteString("@every ")
+	if hours := d / time.Hour; hours > 0 {
+		fmt.Fprintf(&b, "%dh", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		fmt.Fprintf(&b, "%dm", minutes)
+		d -= minutes * time.Minute
+	}
+	if d > 0 || b.Len() == len("@every ") {
+		b.WriteString(d.String())
+	}
+	return b.String()
+}
+
+// IsReboot reports whether the expression is the @reboot macro
+func IsReboot(expr string) bool {
+	return strings.EqualFold(strings.TrimSpace(expr), Reboot)
+}
+
+// Valid reports whether the expression is a valid cron expression
+func Valid(expr string) bool {
+	_, err := Parse(expr)
+	return err == nil
+}
+
+// String returns the fields of the expression, without the time zone. Seconds
+// and years are written only when the expression sets them.
+func (e *Expression) String() string {
+	fields := e.Fields[:]
+	if e.Second != "" {
+		fields = append([]string{e.Second}, fields...)
+	}
+	if e.Year != "" {
+		if e.Second == "" {
+			fields = append([]string{"0"}, fields...)
+		}
+		fields = append(fields, e.Year)
+	}
+	return strings.Join(fields, " ")
+}
+
+// Field returns the normalized value of a field. Unset seconds read as 0 and
+// unset years as *.
+func (e *Expression) Field(f Field) string {
+	switch f {
+	case Second:
+		if e.Second == "" {
+			return "0"
+		}
+		return e.Second
+	case Year:
+		if e.Year == "" {
+			return "*"
+		}
+		return e.Year
+	}
+	return e.Fields[f]
+}
+
+// parseField validates a field and returns its normalized form
+func parseField(f Field, value string) (string, error) {
+	spec := specs[f]
+	parts := strings.Split(value, ",")
+
+	for i, part := range parts {
+		normalized, err := parseTerm(f, spec, part)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = normalized
+	}
+
+	return strings.Join(parts, ","), nil
+}
+
+var (
+	lastOffset  = regexp.MustCompile(`^L-(\d+)$`)
+	nearestDay  = regexp.MustCompile(`^(\d+)W$`)
+	lastWeekday = regexp.MustCompile(`^(\w+)L$`)
+	nthWeekday  = regexp.MustCompile(`^(\w+)#(\d+)$`)
+)
+
+// parseTerm validates one element of a comma separated list
+func parseTerm(f Field, spec fieldSpec, term string) (string, error) {
+	if term == "" {
+		return "", fmt.Errorf("empty value")
+	}
+
+	upper := strings.ToUpper(term)
+	switch f {
+	case DayOfMonth:
+		if upper == "L" || upper == "LW" {
+			return upper, nil
+		}
+		if m := lastOffset.FindStringSubmatch(upper); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n < 1 || n > 30 {
+				return "", fmt.Errorf("offset %s out of range", m[1])
+			}
+			return upper, nil
+		}
+		if m := nearestDay.FindStringSubmatch(upper); m != nil {
+			day, err := parseValue(spec, m[1])
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(day) + "W", nil
+		}
+	case DayOfWeek:
+		if m := lastWeekday.FindStringSubmatch(term); m != nil && m[1] != "" {
+			day, err := parseValue(spec, m[1])
+			if err != nil {
+				return "", err
+			}
+			return strconv.Itoa(day%7) + "L", nil
+		}
+		if m := nthWeekday.FindStringSubmatch(term); m != nil {
+			day, err := parseValue(spec, m[1])
+			if err != nil {
+				return "", err
+			}
+			if n, _ := strconv.Atoi(m[2]); n < 1 || n > 5 {
+				return "", fmt.Errorf("occurrence %s out of range 1-5", m[2])
+			}
+			return strconv.Itoa(day%7) + "#" + m[2], nil
+		}
+	}
+
+	if term == "?" {
+		if f != DayOfMonth && f != DayOfWeek {
+			return "", fmt.Errorf("? is only allowed in day fields")
+		}
+		return "*", nil
+	}
+
+	base, step, hasStep := strings.Cut(term, "/")
+	if hasStep {
+		n, err := strconv.Atoi(step)
+		if err != nil || n < 1 {
+			return "", fmt.Errorf("invalid step %q", step)
+		}
+		if n > spec.max {
+			return "", fmt.Errorf("step %d out of range", n)
+		}
+	}
+
+	if base == "*" {
+		return term, nil
+	}
+
+	from, to, isRange := strings.Cut(base, "-")
+	start, err := parseValue(spec, from)
+	if err != nil {
+		return "", err
+	}
+
+	normalized := strconv.Itoa(start)
+	if isRange {
+		end, err := parseValue(spec, to)
+		if err != nil {
+			return "", err
+		}
+		if end < start {
+			return "", fmt.Errorf("range %s is reversed", base)
+		}
+		normalized += "-" + strconv.Itoa(end)
+	} else if f == DayOfWeek {
+		normalized = strconv.Itoa(start % 7)
+	}
+
+	if hasStep {
+		normalized += "/" + step
+	}
+	return normalized, nil
+}
+
+// parseValue parses a number or a name within the bounds of the field
+func parseValue(spec fieldSpec, value string) (int, error) {
+	if n, ok := spec.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if n < spec.min || n > spec.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, spec.min, spec.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,114 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"0 0 * * 1#1":                        "0 0 * * 1#1",
+		"0 0 * * 5L":                         "0 0 * * 5L",
+		"0 0 15W * *":                        "0 0 15W * *",
+		"0 0 L * *":                          "0 0 L * *",
+		"*/15 * * * *":                       "*/15 * * * *",
+		"0 9-17/2 * jan-mar MON-FRI":         "0 9-17/2 * 1-3 1-5",
+		"0 0 ? * 7":                          "0 0 * * 0",
+		"CRON_TZ=Europe/Amsterdam 0 9 * * *": "0 9 * * *",
+		"0 0 9 * * *":                        "0 9 * * *",
+		"30 */5 * * * ?":                     "30 */5 * * * *",
+		"0 0 3 * * * *":                      "0 3 * * *",
+		"0 0 3 1 1 ? 2027":                   "0 0 3 1 1 * 2027",
+		"@daily":                             "0 0 * * *",
+		"@Weekly":                            "0 0 * * 0",
+		"TZ=UTC @annually":                   "0 0 1 1 *",
+	}
+	for input, want := range tests {
+		e, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", input, err)
+			continue
+		}
+		if e.String() != want {
+			t.Errorf("Parse(%q) = %q, want %q", input, e, want)
+		}
+	}
+
+	for _, input := range []string{"", "0 25 * * *", "0 0 0 0 * * 1", "60 * * * * *", "0 0 0 * * * 1969", "@reboot", "@every 1h", "@fortnightly", "1 2 3 4 5 6 7 8", "0 0 * * 8", "5-1 * * * *", "*/0 * * * *", "0 0 * * 1#6", "TZ=Nowhere/City 0 0 * * *"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	d, err := ParseInterval("@every 1h30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != 90*time.Minute {
+		t.Errorf("ParseInterval() = %s, want 1h30m", d)
+	}
+
+	for _, input := range []string{"@every", "@every soon", "@every 10ms", "@daily", "0 * * * *"} {
+		if _, err := ParseInterval(input); err == nil {
+			t.Errorf("ParseInterval(%q) error = nil, want error", input)
+		}
+	}
+
+	for d, want := range map[time.Duration]string{
+		90 * time.Minute:                    "@every 1h30m",
+		2 * time.Hour:                       "@every 2h",
+		time.Hour + 30*time.Second:          "@every 1h30s",
+		45 * time.Second:                    "@every 45s",
+		time.Minute + 1500*time.Millisecond: "@every 1m1.5s",
+	} {
+		if got := FormatInterval(d); got != want {
+			t.Errorf("FormatInterval(%s) = %q, want %q", d, got, want)
+		}
+		if back, err := ParseInterval(want); err != nil || back != d {
+			t.Errorf("ParseInterval(%q) = %s, %v, want %s", want, back, err, d)
+		}
+	}
+
+	if !IsReboot(" @REBOOT ") || IsReboot("@daily") {
+		t.Error("IsReboot() misreports the macro")
+	}
+}
+
+func TestRenderSecondsAndYears(t *testing.T) {
+	tests := []struct {
+		expr    string
+		dialect Dialect
+		want    string
+	}{
+		{"0 9 * * *", StandardSeconds, "0 0 9 * * *"},
+		{"15 0 9 * * *", StandardSeconds, "15 0 9 * * *"},
+		{"0 0 9 * * * 2027", Dialect{Name: "quartz", Years: true, Seconds: true}, "0 0 9 * * * 2027"},
+		{"0 0 9 * * * 2027", Dialect{Name: "years", Years: true}, "0 0 9 * * * 2027"},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tt.dialect.Render(e)
+		if err != nil {
+			t.Errorf("Render(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"15 0 9 * * *", "0 0 9 * * * 2027"} {
+		e, err := Parse(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Standard.Render(e); err == nil {
+			t.Errorf("Standard.Render(%q) error = nil, want error", input)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dialect describes how an expression is written for a particular scheduler
+type Dialect struct {
+	// Name identifies the dialect
+	Name string
+	// TimezonePrefix is the variable that attaches a time zone to an
+	// expression, such as CRON_TZ. Empty when the scheduler has none.
+	TimezonePrefix string
+	// Seconds is set for schedulers reading a seconds field first, such as
+	// robfig/cron with seconds enabled. Their expressions always have 6 fields.
+	Seconds bool
+	// Years is set for schedulers reading an optional year field last, such as Quartz
+	Years bool
+}
+
+// Standard is the 5-field format understood by cronie, robfig/cron and most
+// other schedulers, with time zones attached as CRON_TZ=
+var Standard = Dialect{Name: "standard", TimezonePrefix: "CRON_TZ"}
+
+// StandardSeconds is the 6-field format of robfig/cron with seconds enabled and
+// of Spring, with seconds first
+var StandardSeconds = Dialect{Name: "standard-seconds", TimezonePrefix: "CRON_TZ", Seconds: true}
+
+// WithTimezonePrefix returns a copy of the dialect using another time zone prefix,
+// for example TZ for schedulers that read the zone from the environment
+func (d Dialect) WithTimezonePrefix(prefix string) Dialect {
+	d.TimezonePrefix = prefix
+	return d
+}
+
+// Render writes the expression in the dialect
+func (d Dialect) Render(e *Expression) (string, error) {
+	fields, err := d.fields(e)
+	if err != nil {
+		return "", err
+	}
+	if e.Location == nil || e.Location == time.Local {
+		return fields, nil
+	}
+
+	if d.TimezonePrefix == "" {
+		return "", fmt.Errorf("dialect %s cannot express time zone %s", d.Name, e.Location)
+	}
+	return d.TimezonePrefix + "=" + e.Location.String() + " " + fields, nil
+}
+
+// fields writes the fields of the expression the dialect reads
+func (d Dialect) fields(e *Expression) (string, error) {
+	if e.Second != "" && !d.Seconds {
+		return "", fmt.Errorf("dialect %s cannot express seconds %s", d.Name, e.Second)
+	}
+	if e.Year != "" && !d.Years {
+		return "", fmt.Errorf("dialect %s cannot express years %s", d.Name, e.Year)
+	}
+
+	fields := e.Fields[:]
+	if d.Seconds || e.Year != "" {
+		fields = append([]string{e.Field(Second)}, fields...)
+	}
+	if e.Year != "" {
+		fields = append(fields, e.Year)
+	}
+	return strings.Join(fields, " "), nil
+}
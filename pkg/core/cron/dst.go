@@ -0,0 +1,165 @@
+package cron
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DSTPolicy decides how local times inside daylight saving transitions fire
+type DSTPolicy int
+
+const (
+	// DSTRunOnce fires every scheduled time exactly once. Times skipped by a
+	// forward transition fire at the transition, times repeated by a backward
+	// transition fire at their first occurrence. This is the behaviour of cronie.
+	DSTRunOnce DSTPolicy = iota
+	// DSTSkip does not fire times skipped by a forward transition, and fires
+	// repeated times at their first occurrence only
+	DSTSkip
+	// DSTRunTwice fires skipped times at the transition, and repeated times
+	// at both of their occurrences
+	DSTRunTwice
+)
+
+// String returns the name of the policy
+func (p DSTPolicy) String() string {
+	switch p {
+	case DSTSkip:
+		return "skip"
+	case DSTRunTwice:
+		return "run-twice"
+	default:
+		return "run-once"
+	}
+}
+
+// ParseDSTPolicy parses a policy name as returned by DSTPolicy.String
+func ParseDSTPolicy(name string) (DSTPolicy, error) {
+	switch name {
+	case "run-once", "":
+		return DSTRunOnce, nil
+	case "skip":
+		return DSTSkip, nil
+	case "run-twice":
+		return DSTRunTwice, nil
+	}
+	return DSTRunOnce, fmt.Errorf("unknown DST policy %q", name)
+}
+
+// wallClockInstants returns every instant showing the wall-clock time in the
+// location: none inside a forward transition, two inside a backward one
+func wallClockInstants(year int, month time.Month, day, hour, minute int, loc *time.Location) []time.Time {
+	wall := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+
+	var result []time.Time
+	// Offsets a day apart cover both sides of any transition near the wall-clock time
+	for _, probe := range []time.Duration{-24 * time.Hour, 24 * time.Hour} {
+		_, offset := wall.Add(probe).In(loc).Zone()
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if t.Hour() != hour || t.Minute() != minute || t.Day() != day {
+			continue
+		}
+		if len(result) == 0 || !result[0].Equal(t) {
+			result = append(result, t)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// resolveWallClock returns the instants a scheduled wall-clock time fires at under the policy
+func resolveWallClock(year int, month time.Month, day, hour, minute int, loc *time.Location, policy DSTPolicy) []time.Time {
+	instants := wallClockInstants(year, month, day, hour, minute, loc)
+
+	switch len(instants) {
+	case 0:
+		if policy == DSTSkip {
+			return nil
+		}
+		// The wall-clock time was skipped, fire when the new offset starts
+		wall := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+		_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+		start, _ := wall.Add(-time.Duration(before) * time.Second).In(loc).ZoneBounds()
+		return []time.Time{start}
+	case 2:
+		if policy != DSTRunTwice {
+			return instants[:1]
+		}
+	}
+
+	return instants
+}
+
+// DSTKind tells whether a transition skips or repeats local time
+type DSTKind int
+
+const (
+	// DSTGap is a forward transition, local times inside it do not exist
+	DSTGap DSTKind = iota
+	// DSTOverlap is a backward transition, local times inside it happen twice
+	DSTOverlap
+)
+
+// String returns the name of the transition kind
+func (k DSTKind) String() string {
+	if k == DSTOverlap {
+		return "overlap"
+	}
+	return "gap"
+}
+
+// DSTConflict is a scheduled local time that falls into a daylight saving transition
+type DSTConflict struct {
+	Kind DSTKind
+	// Transition is the instant the offset changes
+	Transition time.Time
+	// Local is the affected wall-clock time, expressed in UTC fields
+	Local time.Time
+}
+
+// DSTConflicts returns the scheduled local times within the given span after
+// from that are skipped or repeated by daylight saving transitions of the
+// expression's location. It returns nothing for expressions without a location.
+func (e *Expression) DSTConflicts(from time.Time, span time.Duration) []DSTConflict {
+	if e.Location == nil {
+		return nil
+	}
+
+	m := newMatcher(e)
+	var conflicts []DSTConflict
+
+	end := from.Add(span)
+	for t := from.In(e.Location); t.Before(end); {
+		_, next := t.ZoneBounds()
+		if next.IsZero() || !next.Before(end) {
+			break
+		}
+
+		_, before := next.Add(-time.Second).Zone()
+		_, after := next.Zone()
+		kind := DSTGap
+		if after < before {
+			kind = DSTOverlap
+		}
+
+		// The local times between the two offsets are the affected ones
+		lo := next.Add(time.Duration(before) * time.Second).UTC()
+		hi := next.Add(time.Duration(after) * time.Second).UTC()
+		if kind == DSTOverlap {
+			lo, hi = hi, lo
+		}
+
+		for local := lo; local.Before(hi); local = local.Add(time.Minute) {
+			if m.matchDay(local.Year(), local.Month(), local.Day()) &&
+				m.hours[local.Hour()] && m.minutes[local.Minute()] {
+				conflicts = append(conflicts, DSTConflict{Kind: kind, Transition: next, Local: local})
+			}
+		}
+
+		t = next
+	}
+
+	return conflicts
+}
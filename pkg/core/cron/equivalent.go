@@ -0,0 +1,44 @@
+package cron
+
+import "time"
+
+// equivalenceStart and equivalenceYears cover a full 28-year cycle, after
+// which dates fall on the same weekdays again between 1901 and 2099
+const (
+	equivalenceStart = 2001
+	equivalenceYears = 28
+)
+
+// Equivalent reports whether two expressions fire at the same times, however
+// they are written, such as */15 and 0-59/15 or 1-5 and mon-fri. Day fields
+// are compared by evaluating both expressions over a whole calendar cycle.
+func (e *Expression) Equivalent(other *Expression) bool {
+	if locationName(e.Location) != locationName(other.Location) {
+		return false
+	}
+
+	a, b := newMatcher(e), newMatcher(other)
+	if a.seconds != b.seconds || a.minutes != b.minutes || a.hours != b.hours {
+		return false
+	}
+	if a.anyYear != b.anyYear || a.years != b.years {
+		return false
+	}
+
+	date := time.Date(equivalenceStart, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := date.AddDate(equivalenceYears, 0, 0)
+	for ; date.Before(end); date = date.AddDate(0, 0, 1) {
+		year, month, day := date.Date()
+		if a.matchDate(year, month, day) != b.matchDate(year, month, day) {
+			return false
+		}
+	}
+	return true
+}
+
+func locationName(loc *time.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.String()
+}
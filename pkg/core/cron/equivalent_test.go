@@ -0,0 +1,45 @@
+package cron
+
+import "testing"
+
+func TestEquivalent(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"*/15 * * * *", "0-59/15 * * * *", true},
+		{"0,15,30,45 * * * *", "*/15 * * * *", true},
+		{"0 9 * * 1-5", "0 9 * * mon-fri", true},
+		{"0 9 * * 1-5", "0 9 ? * MON,TUE,WED,THU,FRI", true},
+		{"0 0 * * 0", "0 0 * * 7", true},
+		{"0 0 1 jan *", "0 0 1 1 *", true},
+		{"0 0 * * *", "0 0 1-31 * *", true},
+		{"0 0 L * *", "0 0 28-31 * *", false},
+		{"0 0 * * 1", "0 0 1-31 * 1", false},
+		{"0 9 * * 1-5", "0 9 * * 1-6", false},
+		{"0 9 * * *", "0 21 * * *", false},
+		{"0 9 * * *", "CRON_TZ=Europe/Amsterdam 0 9 * * *", false},
+		{"CRON_TZ=UTC 0 9 * * *", "TZ=UTC 0 9 * * *", true},
+		{"@daily", "0 0 0 * * ?", true},
+		{"30 0 9 * * *", "0 9 * * *", false},
+		{"0 0 9 * * * 2027", "0 9 * * *", false},
+		{"0 0 9 * * * 2027-2028", "0 0 9 * * * 2027,2028", true},
+	}
+
+	for _, tt := range tests {
+		a, err := Parse(tt.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Parse(tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Equivalent(b); got != tt.want {
+			t.Errorf("%q equivalent to %q = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := b.Equivalent(a); got != tt.want {
+			t.Errorf("%q equivalent to %q = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
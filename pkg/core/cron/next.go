@@ -0,0 +1,290 @@
+package cron
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchYears limits how far ahead Next looks for a matching day
+const searchYears = 5
+
+// matcher is the expanded form of an expression used to evaluate it
+type matcher struct {
+	seconds [60]bool
+	minutes [60]bool
+	hours   [24]bool
+	months  [13]bool
+
+	days      [32]bool
+	lastDay   bool
+	lastWeek  bool // LW, the last weekday of the month
+	lastMinus []int
+	nearest   []int
+
+	weekdays [7]bool
+	lastOf   []time.Weekday
+	nth      map[time.Weekday][]int
+
+	anyDay, anyWeekday bool
+
+	years   [maxYear - minYear + 1]bool
+	anyYear bool
+}
+
+// newMatcher expands the normalized fields of an expression
+func newMatcher(e *Expression) *matcher {
+	m := &matcher{
+		nth:        make(map[time.Weekday][]int),
+		anyDay:     e.Fields[DayOfMonth] == "*",
+		anyWeekday: e.Fields[DayOfWeek] == "*",
+		anyYear:    e.Year == "",
+	}
+
+	expand(e.Field(Second), 0, 59, m.seconds[:])
+	if !m.anyYear {
+		expandYears(e.Year, m.years[:])
+	}
+	expand(e.Fields[Minute], 0, 59, m.minutes[:])
+	expand(e.Fields[Hour], 0, 23, m.hours[:])
+	expand(e.Fields[Month], 1, 12, m.months[:])
+
+	for _, term := range strings.Split(e.Fields[DayOfMonth], ",") {
+		switch {
+		case term == "L":
+			m.lastDay = true
+		case term == "LW":
+			m.lastWeek = true
+		case strings.HasPrefix(term, "L-"):
+			n, _ := strconv.Atoi(term[2:])
+			m.lastMinus = append(m.lastMinus, n)
+		case strings.HasSuffix(term, "W"):
+			n, _ := strconv.Atoi(strings.TrimSuffix(term, "W"))
+			m.nearest = append(m.nearest, n)
+		default:
+			expand(term, 1, 31, m.days[:])
+		}
+	}
+
+	for _, term := range strings.Split(e.Fields[DayOfWeek], ",") {
+		switch {
+		case strings.HasSuffix(term, "L"):
+			n, _ := strconv.Atoi(strings.TrimSuffix(term, "L"))
+			m.lastOf = append(m.lastOf, time.Weekday(n))
+		case strings.Contains(term, "#"):
+			day, nth, _ := strings.Cut(term, "#")
+			d, _ := strconv.Atoi(day)
+			n, _ := strconv.Atoi(nth)
+			m.nth[time.Weekday(d)] = append(m.nth[time.Weekday(d)], n)
+		default:
+			var days [8]bool
+			expand(term, 0, 7, days[:])
+			for d, ok := range days {
+				if ok {
+					m.weekdays[d%7] = true
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+// expand marks the values of a normalized term, or list of terms, in set
+func expand(field string, lo, hi int, set []bool) {
+	for _, term := range strings.Split(field, ",") {
+		base, stepText, hasStep := strings.Cut(term, "/")
+		step := 1
+		if hasStep {
+			step, _ = strconv.Atoi(stepText)
+		}
+
+		start, end := lo, hi
+		if base != "*" {
+			from, to, isRange := strings.Cut(base, "-")
+			start, _ = strconv.Atoi(from)
+			end = start
+			if isRange {
+				end, _ = strconv.Atoi(to)
+			} else if hasStep {
+				end = hi
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			set[v] = true
+		}
+	}
+}
+
+// expandYears marks the years of a normalized year field, offset from minYear
+func expandYears(field string, set []bool) {
+	var years [maxYear + 1]bool
+	expand(field, minYear, maxYear, years[:])
+	copy(set, years[minYear:])
+}
+
+// matchYear reports whether the schedule fires in the given year
+func (m *matcher) matchYear(year int) bool {
+	if m.anyYear {
+		return true
+	}
+	return year >= minYear && year <= maxYear && m.years[year-minYear]
+}
+
+// matchDay reports whether the schedule fires on the given date
+func (m *matcher) matchDay(year int, month time.Month, day int) bool {
+	return m.matchYear(year) && m.matchDate(year, month, day)
+}
+
+// matchDate reports whether the schedule fires on the given date of any year
+// it runs in. Like most cron implementations, a day matches either field when
+// both the day of month and the day of week are restricted.
+func (m *matcher) matchDate(year int, month time.Month, day int) bool {
+	if !m.months[month] {
+		return false
+	}
+
+	switch {
+	case m.anyDay && m.anyWeekday:
+		return true
+	case m.anyDay:
+		return m.matchWeekday(year, month, day)
+	case m.anyWeekday:
+		return m.matchDayOfMonth(year, month, day)
+	default:
+		return m.matchDayOfMonth(year, month, day) || m.matchWeekday(year, month, day)
+	}
+}
+
+func (m *matcher) matchDayOfMonth(year int, month time.Month, day int) bool {
+	last := daysIn(year, month)
+	if m.days[day] || (m.lastDay && day == last) {
+		return true
+	}
+	for _, n := range m.lastMinus {
+		if day == last-n {
+			return true
+		}
+	}
+	if m.lastWeek && day == nearestWeekday(year, month, last) {
+		return true
+	}
+	for _, n := range m.nearest {
+		if n <= last && day == nearestWeekday(year, month, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *matcher) matchWeekday(year int, month time.Month, day int) bool {
+	weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+	if m.weekdays[weekday] {
+		return true
+	}
+	for _, d := range m.lastOf {
+		if d == weekday && day+7 > daysIn(year, month) {
+			return true
+		}
+	}
+	for _, n := range m.nth[weekday] {
+		if (day-1)/7+1 == n {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestWeekday returns the weekday closest to the day without leaving the month
+func nearestWeekday(year int, month time.Month, day int) int {
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == daysIn(year, month) {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Next returns the first time after the given instant at which the expression
+// fires, or the zero time when it never does. The expression is evaluated in
+// its location, or in the location of after when it has none. Local times
+// that fall into daylight saving transitions are handled according to policy.
+func (e *Expression) Next(after time.Time, policy DSTPolicy) time.Time {
+	loc := e.Location
+	if loc == nil {
+		loc = after.Location()
+	}
+
+	m := newMatcher(e)
+	local := after.In(loc)
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	end := date.AddDate(searchYears, 0, 0)
+	if !m.anyYear {
+		end = time.Date(maxYear+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	for ; date.Before(end); date = date.AddDate(0, 0, 1) {
+		if !m.matchYear(date.Year()) {
+			// Jump to the last day of the year, the loop moves on to the next one
+			date = time.Date(date.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !m.matchDay(date.Year(), date.Month(), date.Day()) {
+			continue
+		}
+
+		for _, t := range m.instants(date, loc, policy) {
+			if t.After(after) {
+				return t
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// instants returns the sorted instants at which the schedule fires on a matching day
+func (m *matcher) instants(date time.Time, loc *time.Location, policy DSTPolicy) []time.Time {
+	var result []time.Time
+	seen := make(map[int64]bool)
+
+	for h := 0; h < 24; h++ {
+		if !m.hours[h] {
+			continue
+		}
+		for minute := 0; minute < 60; minute++ {
+			if !m.minutes[minute] {
+				continue
+			}
+
+			for _, t := range resolveWallClock(date.Year(), date.Month(), date.Day(), h, minute, loc, policy) {
+				for second := 0; second < 60; second++ {
+					if !m.seconds[second] {
+						continue
+					}
+					at := t.Add(time.Duration(second) * time.Second)
+					if !seen[at.Unix()] {
+						seen[at.Unix()] = true
+						result = append(result, at)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
@@ -0,0 +1,95 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"0 9 * * *", "2025-01-15T10:00:00Z", "2025-01-16T09:00:00Z"},
+		{"*/15 * * * *", "2025-01-15T10:07:00Z", "2025-01-15T10:15:00Z"},
+		{"0 0 * * 1#1", "2025-01-15T00:00:00Z", "2025-02-03T00:00:00Z"},
+		{"0 0 * * 5L", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z"},
+		{"0 0 L * *", "2025-02-01T00:00:00Z", "2025-02-28T00:00:00Z"},
+		{"0 0 15W * *", "2025-03-01T00:00:00Z", "2025-03-14T00:00:00Z"},
+		{"0 0 1W * *", "2025-01-02T00:00:00Z", "2025-02-03T00:00:00Z"},
+		{"0 0 29 2 *", "2025-01-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"0 0 13 * 5", "2025-01-01T00:00:00Z", "2025-01-03T00:00:00Z"},
+		{"*/20 * * * * *", "2025-01-15T10:07:45Z", "2025-01-15T10:08:00Z"},
+		{"30 0 9 * * *", "2025-01-15T09:00:30Z", "2025-01-16T09:00:30Z"},
+		{"0 0 0 1 1 * 2040", "2025-01-01T00:00:00Z", "2040-01-01T00:00:00Z"},
+		{"0 0 0 1 1 * 2020", "2025-01-01T00:00:00Z", "0001-01-01T00:00:00Z"},
+		{"@monthly", "2025-01-15T00:00:00Z", "2025-02-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, _ := time.Parse(time.RFC3339, tt.after)
+
+			got := e.Next(after, DSTRunOnce)
+			if got.Format(time.RFC3339) != tt.want {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Parse("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Location = amsterdam
+
+	runs := func(from string, policy DSTPolicy, n int) []string {
+		after, _ := time.Parse(time.RFC3339, from)
+		var result []string
+		for i := 0; i < n; i++ {
+			after = e.Next(after, policy)
+			result = append(result, after.Format(time.RFC3339))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		from   string
+		policy DSTPolicy
+		want   []string
+	}{
+		{"gap run once", "2025-03-29T12:00:00+01:00", DSTRunOnce, []string{"2025-03-30T03:00:00+02:00", "2025-03-31T02:30:00+02:00"}},
+		{"gap skip", "2025-03-29T12:00:00+01:00", DSTSkip, []string{"2025-03-31T02:30:00+02:00", "2025-04-01T02:30:00+02:00"}},
+		{"overlap run once", "2025-10-25T12:00:00+02:00", DSTRunOnce, []string{"2025-10-26T02:30:00+02:00", "2025-10-27T02:30:00+01:00"}},
+		{"overlap run twice", "2025-10-25T12:00:00+02:00", DSTRunTwice, []string{"2025-10-26T02:30:00+02:00", "2025-10-26T02:30:00+01:00", "2025-10-27T02:30:00+01:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runs(tt.from, tt.policy, len(tt.want))
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("run %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	conflicts := e.DSTConflicts(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), 365*24*time.Hour)
+	if len(conflicts) != 2 || conflicts[0].Kind != DSTGap || conflicts[1].Kind != DSTOverlap {
+		t.Errorf("DSTConflicts() = %+v, want a gap and an overlap", conflicts)
+	}
+}
@@ -0,0 +1,253 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShiftError is returned when an expression cannot be moved to another time zone
+// without changing its meaning
+type ShiftError struct {
+	Expression string
+	Reason     string
+}
+
+func (e *ShiftError) Error() string {
+	return fmt.Sprintf("cannot shift %q to another time zone: %s", e.Expression, e.Reason)
+}
+
+// Shift moves the minute, hour and day fields of the expression from its own
+// location to the target location, using the offsets of both zones at the given
+// instant. It returns the shifted expression and by how many days the schedule
+// moved, which is non-zero when the shift crossed midnight.
+func (e *Expression) Shift(target *time.Location, at time.Time) (*Expression, int, error) {
+	if e.Location == nil {
+		return nil, 0, &ShiftError{Expression: e.String(), Reason: "expression has no time zone"}
+	}
+
+	_, sourceOffset := at.In(e.Location).Zone()
+	_, targetOffset := at.In(target).Zone()
+	delta := (targetOffset - sourceOffset) / 60
+
+	shifted := &Expression{Fields: e.Fields, Second: e.Second, Year: e.Year, Location: target}
+	if delta == 0 {
+		return shifted, 0, nil
+	}
+
+	fail := func(format string, args ...any) (*Expression, int, error) {
+		return nil, 0, &ShiftError{Expression: e.String(), Reason: fmt.Sprintf(format, args...)}
+	}
+
+	minutes, carry, err := shiftMinutes(e.Fields[Minute], delta)
+	if err != nil {
+		return fail("%v", err)
+	}
+	shifted.Fields[Minute] = minutes
+
+	daysRestricted := e.Fields[DayOfMonth] != "*" || e.Fields[DayOfWeek] != "*"
+	hours, days, err := shiftHours(e.Fields[Hour], carry)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if days == anyDay && carry != 0 && daysRestricted {
+		return fail("hours %s cross midnight on restricted days", e.Fields[Hour])
+	}
+	shifted.Fields[Hour] = hours
+
+	if days != anyDay && days != 0 && e.Year != "" {
+		return fail("years %s cannot move across year boundaries", e.Year)
+	}
+
+	if days == anyDay || days == 0 || !daysRestricted {
+		if days == anyDay {
+			days = 0
+		}
+		return shifted, days, nil
+	}
+
+	if e.Fields[DayOfMonth] != "*" {
+		dom, err := shiftDaysOfMonth(e.Fields[DayOfMonth], days)
+		if err != nil {
+			return fail("%v", err)
+		}
+		shifted.Fields[DayOfMonth] = dom
+	}
+
+	if e.Fields[DayOfWeek] != "*" {
+		if e.Fields[DayOfMonth] == "*" && e.Fields[Month] != "*" {
+			return fail("weekdays of restricted months cannot move across month boundaries")
+		}
+		dow, err := shiftDaysOfWeek(e.Fields[DayOfWeek], days)
+		if err != nil {
+			return fail("%v", err)
+		}
+		shifted.Fields[DayOfWeek] = dow
+	}
+
+	return shifted, days, nil
+}
+
+// anyDay marks hour fields that fire around the clock, so their day shift is undefined
+const anyDay = 1 << 30
+
+// shiftMinutes shifts the minute field and returns the hours carried into the hour field
+func shiftMinutes(field string, delta int) (string, int, error) {
+	if delta%60 == 0 {
+		return field, delta / 60, nil
+	}
+
+	values, ok := plainValues(field)
+	if !ok {
+		return "", 0, fmt.Errorf("minutes %s cannot move by %d minutes", field, delta)
+	}
+
+	carry := floorDiv(values[0]+delta, 60)
+	shifted := make([]string, len(values))
+	for i, v := range values {
+		if floorDiv(v+delta, 60) != carry {
+			return "", 0, fmt.Errorf("minutes %s fall into different hours after the shift", field)
+		}
+		shifted[i] = strconv.Itoa(mod(v+delta, 60))
+	}
+	return strings.Join(shifted, ","), carry, nil
+}
+
+// shiftHours shifts the hour field and returns by how many days it moved
+func shiftHours(field string, carry int) (string, int, error) {
+	if carry == 0 {
+		return field, 0, nil
+	}
+
+	days := 0
+	seen := false
+	terms := strings.Split(field, ",")
+	for i, term := range terms {
+		base, step, hasStep := strings.Cut(term, "/")
+		var shifted string
+		termDays := anyDay
+
+		switch {
+		case base == "*" && !hasStep:
+			shifted = term
+		case base == "*":
+			n, _ := strconv.Atoi(step)
+			if 24%n != 0 {
+				return "", 0, fmt.Errorf("hours %s do not repeat daily", term)
+			}
+			start := mod(carry, n)
+			if start == 0 {
+				shifted = term
+			} else {
+				shifted = fmt.Sprintf("%d-23/%s", start, step)
+			}
+		default:
+			from, to, isRange := strings.Cut(base, "-")
+			start, _ := strconv.Atoi(from)
+			end := start
+			if isRange {
+				end, _ = strconv.Atoi(to)
+			}
+
+			termDays = floorDiv(start+carry, 24)
+			if floorDiv(end+carry, 24) != termDays {
+				return "", 0, fmt.Errorf("hours %s cross midnight after the shift", term)
+			}
+
+			shifted = strconv.Itoa(mod(start+carry, 24))
+			if isRange {
+				shifted += "-" + strconv.Itoa(mod(end+carry, 24))
+			}
+			if hasStep {
+				shifted += "/" + step
+			}
+		}
+
+		if termDays != anyDay {
+			if seen && termDays != days {
+				return "", 0, fmt.Errorf("hours %s fall on different days after the shift", field)
+			}
+			days, seen = termDays, true
+		}
+		terms[i] = shifted
+	}
+
+	if !seen {
+		days = anyDay
+	}
+	return strings.Join(terms, ","), days, nil
+}
+
+// shiftDaysOfMonth moves plain days of month that stay within every month
+func shiftDaysOfMonth(field string, days int) (string, error) {
+	values, ok := plainValues(field)
+	if !ok {
+		return "", fmt.Errorf("days of month %s cannot move by %d days", field, days)
+	}
+
+	shifted := make([]string, len(values))
+	for i, v := range values {
+		if v+days < 1 || v+days > 28 {
+			return "", fmt.Errorf("day of month %d moves across a month boundary", v)
+		}
+		shifted[i] = strconv.Itoa(v + days)
+	}
+	return strings.Join(shifted, ","), nil
+}
+
+// shiftDaysOfWeek moves days of week, ranges that wrap around are expanded into lists
+func shiftDaysOfWeek(field string, days int) (string, error) {
+	var shifted []string
+	for _, term := range strings.Split(field, ",") {
+		from, to, isRange := strings.Cut(term, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil || strings.Contains(term, "/") {
+			return "", fmt.Errorf("days of week %s cannot move by %d days", field, days)
+		}
+
+		if !isRange {
+			shifted = append(shifted, strconv.Itoa(mod(start+days, 7)))
+			continue
+		}
+
+		end, err := strconv.Atoi(to)
+		if err != nil {
+			return "", fmt.Errorf("days of week %s cannot move by %d days", field, days)
+		}
+		if start+days >= 0 && end+days <= 6 {
+			shifted = append(shifted, fmt.Sprintf("%d-%d", start+days, end+days))
+			continue
+		}
+		for v := start; v <= end; v++ {
+			shifted = append(shifted, strconv.Itoa(mod(v+days, 7)))
+		}
+	}
+	return strings.Join(shifted, ","), nil
+}
+
+// plainValues returns the values of a field that is a list of plain numbers
+func plainValues(field string) ([]int, bool) {
+	parts := strings.Split(field, ",")
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func mod(a, b int) int {
+	return ((a % b) + b) % b
+}
@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShift(t *testing.T) {
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	newYork, _ := time.LoadLocation("America/New_York")
+	at := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr    string
+		from    *time.Location
+		want    string
+		days    int
+		wantErr bool
+	}{
+		{expr: "30 9 * * *", from: kolkata, want: "0 4 * * *"},
+		{expr: "0 22 * * 1-5", from: newYork, want: "0 3 * * 2-6", days: 1},
+		{expr: "0 22 * * 5,6", from: newYork, want: "0 3 * * 6,0", days: 1},
+		{expr: "0 9-17 * * *", from: newYork, want: "0 14-22 * * *"},
+		{expr: "*/15 * * * *", from: newYork, want: "*/15 * * * *"},
+		{expr: "0 */5 * * *", from: newYork, wantErr: true},
+		{expr: "0 20-23 * * 1", from: newYork, want: "0 1-4 * * 2", days: 1},
+		{expr: "0 18-21 * * 1", from: newYork, wantErr: true},
+		{expr: "0 22 * * 5L", from: newYork, wantErr: true},
+		{expr: "0 22 L * *", from: newYork, wantErr: true},
+		{expr: "0,15 9 * * *", from: kolkata, want: "30,45 3 * * *"},
+		{expr: "0,45 9 * * *", from: kolkata, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.Location = tt.from
+
+			shifted, days, err := e.Shift(time.UTC, at)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Shift() = %q, want error", shifted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Shift() error = %v", err)
+			}
+			if shifted.String() != tt.want || days != tt.days {
+				t.Errorf("Shift() = %q, %d, want %q, %d", shifted, days, tt.want, tt.days)
+			}
+		})
+	}
+}
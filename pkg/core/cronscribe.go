@@ -1,33 +1,287 @@
 package core
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cache"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	R "github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
 // Version is the current version of the CronScribe core package
 const Version = "1.0.0"
 
+// Option represents a functional option for configuring CronScribe
+type Option func(*CronScribe)
+
+// WithLocation sets the time zone of expressions that do not name one
+func WithLocation(loc *time.Location) Option {
+	return func(c *CronScribe) {
+		c.location = loc
+	}
+}
+
+// WithTargetLocation makes schedules move into the given time zone, typically
+// the zone of the scheduler, instead of carrying a time zone prefix
+func WithTargetLocation(loc *time.Location) Option {
+	return func(c *CronScribe) {
+		c.targetLocation = loc
+	}
+}
+
+// WithDialect sets the dialect cron expressions are rendered in
+func WithDialect(dialect cron.Dialect) Option {
+	return func(c *CronScribe) {
+		c.dialect = dialect
+	}
+}
+
+// WithDSTPolicy sets how scheduled local times inside daylight saving
+// transitions fire, see cron.DSTPolicy. The default is cron.DSTRunOnce.
+func WithDSTPolicy(policy cron.DSTPolicy) Option {
+	return func(c *CronScribe) {
+		c.dstPolicy = policy
+	}
+}
+
+// WithCache caches conversions in the given store. Entries are keyed by the
+// input, the language, the dialect, the time zone settings and the version of
+// the rules, so adding or reloading rules invalidates them. Inputs no rule
+// matches are cached too, for the negative TTL.
+func WithCache(store cache.Store, options ...cache.Option) Option {
+	return func(c *CronScribe) {
+		c.cache = cache.New(store, options...)
+	}
+}
+
+// WithDescription makes conversions describe the schedule back in words, in
+// Result.Description. Inputs written in cron syntax are described in the
+// current language, text in the language it was written in.
+func WithDescription() Option {
+	return func(c *CronScribe) {
+		c.describe = true
+	}
+}
+
 // CronScribe is the main entry point for using the core functionality
 type CronScribe struct {
-	mapper *HumanCronMapper
+	mapper         *HumanCronMapper
+	location       *time.Location
+	targetLocation *time.Location
+	dialect        cron.Dialect
+	dstPolicy      cron.DSTPolicy
+	now            func() time.Time
+	cache          *cache.Cache
+	describe       bool
 }
 
 // New creates a new CronScribe instance
-func New(rulesDir string) (*CronScribe, error) {
+func New(rulesDir string, options ...Option) (*CronScribe, error) {
 	mapper, err := NewHumanCronMapper(rulesDir)
 	if err != nil {
 		return nil, err
 	}
 
-	return &CronScribe{
-		mapper: mapper,
-	}, nil
+	c := &CronScribe{
+		mapper:  mapper,
+		dialect: cron.Standard,
+		now:     time.Now,
+	}
+
+	// Apply all options
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
 }
 
-// Convert transforms a human-readable scheduling expression to a cron expression
+// Convert transforms a human-readable scheduling expression to a cron
+// expression. Inputs already written as cron expressions or macros are
+// validated and normalized instead.
 func (c *CronScribe) Convert(expression string) (string, error) {
-	return c.mapper.ToCron(expression)
+	result, err := c.ConvertResult(expression)
+	if err != nil {
+		return "", err
+	}
+	return result.Expression, nil
+}
+
+// ConvertResult transforms a human-readable scheduling expression to a cron
+// expression and reports how it was converted
+func (c *CronScribe) ConvertResult(expression string) (*Result, error) {
+	return c.convert(expression, false)
 }
 
 // AutoDetect tries to automatically detect the language and convert the expression
 func (c *CronScribe) AutoDetect(expression string) (string, error) {
-	return c.mapper.AutoDetectAndConvert(expression)
+	result, err := c.AutoDetectResult(expression)
+	if err != nil {
+		return "", err
+	}
+	return result.Expression, nil
+}
+
+// AutoDetectResult is AutoDetect that reports how the expression was converted
+func (c *CronScribe) AutoDetectResult(expression string) (*Result, error) {
+	return c.convert(expression, true)
+}
+
+// cachedFailure is the cached form of a conversion no rule matched
+type cachedFailure struct {
+	Message string `json:"message"`
+}
+
+// convert normalizes inputs written in cron syntax and converts the others
+func (c *CronScribe) convert(expression string, autoDetect bool) (*Result, error) {
+	var (
+		result *Result
+		err    error
+	)
+	if syntax := DetectSyntax(expression); syntax != SyntaxNatural {
+		result, err = c.convertSyntax(expression, syntax)
+	} else {
+		result, err = c.convertCached(expression, autoDetect)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.describe {
+		c.addDescription(result)
+	}
+	return result, nil
+}
+
+// addDescription describes the schedule of a result, leaving the description
+// empty for results without a calendar schedule or in languages schedules
+// cannot be described in
+func (c *CronScribe) addDescription(result *Result) {
+	s := result.Schedule()
+	if s == nil {
+		return
+	}
+
+	language := result.Language
+	if language == "" {
+		language = c.Language()
+	}
+	if description, err := s.Describe(language); err == nil {
+		result.Description = description
+	}
+}
+
+// convertCached serves a conversion from the cache, or runs it and caches the outcome
+func (c *CronScribe) convertCached(expression string, autoDetect bool) (*Result, error) {
+	if c.cache == nil {
+		return c.convertUncached(expression, autoDetect)
+	}
+
+	key := c.CacheKey(expression, autoDetect)
+
+	if entry, ok := c.cache.Get(key); ok {
+		if entry.Failure {
+			var failed cachedFailure
+			if entry.Decode(&failed) == nil {
+				return nil, &cache.Error{Message: failed.Message, Err: R.ErrNoMatch}
+			}
+		} else {
+			var result Result
+			if entry.Decode(&result) == nil {
+				return &result, nil
+			}
+		}
+	}
+
+	converted, err := c.convertUncached(expression, autoDetect)
+	switch {
+	case err == nil:
+		c.cache.Put(key, converted)
+	case errors.Is(err, R.ErrNoMatch):
+		c.cache.PutFailure(key, cachedFailure{Message: err.Error()})
+	}
+	return converted, err
+}
+
+// CacheKey returns the cache key of an input with the current settings and
+// date: shifts into the target zone and DST warnings are computed for the
+// date, so entries are not reused on other days. Layers caching on top of
+// CronScribe build their keys from it.
+func (c *CronScribe) CacheKey(expression string, autoDetect bool) string {
+	language := c.Language()
+	if autoDetect {
+		language = "*"
+	}
+
+	return cache.Key(
+		normalizeInput(expression),
+		language,
+		c.dialect.Name,
+		c.dialect.TimezonePrefix,
+		locationName(c.location),
+		locationName(c.targetLocation),
+		c.dstPolicy.String(),
+		c.mapper.Version(),
+		c.now().UTC().Format(time.DateOnly),
+	)
+}
+
+// normalizeInput lowercases and trims an input the way rules see it, keeping
+// the case of IANA zone names
+func normalizeInput(expression string) string {
+	expression = strings.TrimSpace(expression)
+	if m := ianaZone.FindStringSubmatchIndex(expression); m != nil {
+		return strings.ToLower(expression[:m[2]]) + expression[m[2]:m[3]] + strings.ToLower(expression[m[3]:])
+	}
+	return strings.ToLower(expression)
+}
+
+func locationName(loc *time.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.String()
+}
+
+// convertUncached runs the whole conversion: time zone detection, rule
+// matching, validation and rendering in the configured dialect
+func (c *CronScribe) convertUncached(expression string, autoDetect bool) (*Result, error) {
+	loc, text, err := detectLocation(expression, c.mapper.timezones(autoDetect))
+	if err != nil {
+		return nil, err
+	}
+
+	cronExpr, rule, rules, err := c.mapper.match(text, autoDetect)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := cron.Parse(cronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s produced an invalid expression: %w", rule.Name, err)
+	}
+
+	result := &Result{
+		Syntax:   SyntaxNatural,
+		Language: rules.Language,
+		Rule:     rule.Name,
+		Quality:  rule.Quality(text, rules.Dictionaries),
+		policy:   c.dstPolicy,
+	}
+	if err := c.applyLocation(expr, loc, result); err != nil {
+		return nil, err
+	}
+
+	result.schedule = &cron.Expression{Fields: expr.Fields, Second: expr.Second, Year: expr.Year, Location: result.Location}
+
+	result.Expression, err = c.dialect.Render(expr)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // SetLanguage sets the language for processing expressions
@@ -35,6 +289,37 @@ func (c *CronScribe) SetLanguage(lang string) error {
 	return c.mapper.SetLanguage(lang)
 }
 
+// Language returns the current language
+func (c *CronScribe) Language() string {
+	return c.mapper.Language()
+}
+
+// Dialect returns the dialect cron expressions are rendered in
+func (c *CronScribe) Dialect() cron.Dialect {
+	return c.dialect
+}
+
+// Location returns the time zone of expressions that do not name one, nil when unset
+func (c *CronScribe) Location() *time.Location {
+	return c.location
+}
+
+// RulesVersion identifies the loaded rules, it changes whenever rules are added or reloaded
+func (c *CronScribe) RulesVersion() string {
+	return c.mapper.Version()
+}
+
+// ReloadRules loads the rules from the rules directory again, invalidating
+// cached conversions. Rules added from other files or from Go code are dropped.
+func (c *CronScribe) ReloadRules() error {
+	return c.mapper.Reload()
+}
+
+// RuleSet returns the rules loaded for a language, or nil when there are none
+func (c *CronScribe) RuleSet(language string) *R.Rules {
+	return c.mapper.allRules[language]
+}
+
 // GetSupportedLanguages returns a list of supported languages
 func (c *CronScribe) GetSupportedLanguages() []string {
 	return c.mapper.GetSupportedLanguages()
@@ -43,4 +328,9 @@ func (c *CronScribe) GetSupportedLanguages() []string {
 // AddRulesFromFile adds rules from a file
 func (c *CronScribe) AddRulesFromFile(filePath string) error {
 	return c.mapper.AddRulesFromFile(filePath)
-}
\ No newline at end of file
+}
+
+// AddRules adds rules built in Go code, see rules.NewRules
+func (c *CronScribe) AddRules(rules *R.Rules) error {
+	return c.mapper.AddRules(rules)
+}
@@ -1,15 +1,21 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	R "github.com/flaticols/cronscribe/pkg/core/rules"
+	"sort"
 	"strings"
 )
 
 // HumanCronMapper converts human-readable scheduling expressions to cron format
 type HumanCronMapper struct {
+	rulesDir     string
 	allRules     map[string]*R.Rules
 	currentRules *R.Rules
+	version      string
 }
 
 // NewHumanCronMapper creates a new mapper instance
@@ -20,21 +26,63 @@ func NewHumanCronMapper(rulesDir string) (*HumanCronMapper, error) {
 	}
 
 	mapper := &HumanCronMapper{
+		rulesDir: rulesDir,
 		allRules: allRules,
 	}
 
-	// By default, use English rules if available
+	mapper.currentRules = defaultRules(allRules)
+	mapper.updateVersion()
+	return mapper, nil
+}
+
+// defaultRules returns the English rules if available, otherwise the first available rules
+func defaultRules(allRules map[string]*R.Rules) *R.Rules {
 	if rules, ok := allRules["en"]; ok {
-		mapper.currentRules = rules
-	} else {
-		// Otherwise use the first available rules
-		for _, rules := range allRules {
-			mapper.currentRules = rules
-			break
-		}
+		return rules
 	}
+	for _, rules := range allRules {
+		return rules
+	}
+	return nil
+}
 
-	return mapper, nil
+// Reload loads the rules from the rules directory again. Rules added from
+// other files or from Go code are dropped.
+func (m *HumanCronMapper) Reload() error {
+	allRules, err := R.LoadAllRules(m.rulesDir)
+	if err != nil {
+		return err
+	}
+
+	language := m.Language()
+	m.allRules = allRules
+	m.currentRules = allRules[language]
+	if m.currentRules == nil {
+		m.currentRules = defaultRules(allRules)
+	}
+
+	m.updateVersion()
+	return nil
+}
+
+// Version identifies the loaded rule sets, it changes whenever rules are added or reloaded
+func (m *HumanCronMapper) Version() string {
+	return m.version
+}
+
+// updateVersion recomputes the version from the fingerprints of all rule sets
+func (m *HumanCronMapper) updateVersion() {
+	languages := make([]string, 0, len(m.allRules))
+	for lang := range m.allRules {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	h := sha256.New()
+	for _, lang := range languages {
+		fmt.Fprintf(h, "%s=%s\n", lang, m.allRules[lang].Fingerprint())
+	}
+	m.version = hex.EncodeToString(h.Sum(nil)[:8])
 }
 
 // SetLanguage sets the language for the mapper
@@ -48,43 +96,77 @@ func (m *HumanCronMapper) SetLanguage(lang string) error {
 	return nil
 }
 
-// ToCron converts a human-readable expression to cron format
-func (m *HumanCronMapper) ToCron(expression string) (string, error) {
+// Language returns the current language, or an empty string when no rules are loaded
+func (m *HumanCronMapper) Language() string {
 	if m.currentRules == nil {
-		return "", fmt.Errorf("rules not loaded")
-	}
-
-	// Convert the expression to lowercase for standardization
-	expr := strings.ToLower(strings.TrimSpace(expression))
-
-	// Go through all rules and try to find a match
-	for _, rule := range m.currentRules.Rules {
-		if match := rule.Match(expr); match != nil {
-			return TranslateRule(&rule, match, m.currentRules.Dictionaries)
-		}
+		return ""
 	}
+	return m.currentRules.Language
+}
 
-	return "", fmt.Errorf("unsupported expression format: %s", expression)
+// ToCron converts a human-readable expression to cron format
+func (m *HumanCronMapper) ToCron(expression string) (string, error) {
+	cronExpr, _, _, err := m.match(expression, false)
+	return cronExpr, err
 }
 
 // AutoDetectAndConvert tries to automatically detect the language and convert the expression
 func (m *HumanCronMapper) AutoDetectAndConvert(expression string) (string, error) {
+	cronExpr, _, _, err := m.match(expression, true)
+	return cronExpr, err
+}
+
+// match converts an expression with the current language, or with the first
+// language that has a matching rule, and returns the rule that produced it
+func (m *HumanCronMapper) match(expression string, autoDetect bool) (string, *R.Rule, *R.Rules, error) {
+	if !autoDetect {
+		if m.currentRules == nil {
+			return "", nil, nil, fmt.Errorf("rules not loaded")
+		}
+
+		cronExpr, rule, err := m.currentRules.Convert(expression)
+		return cronExpr, rule, m.currentRules, err
+	}
+
 	expr := strings.ToLower(strings.TrimSpace(expression))
 
 	// Go through all languages
 	for _, rules := range m.allRules {
-		for _, rule := range rules.Rules {
+		for i := range rules.Rules {
+			rule := &rules.Rules[i]
 			if match := rule.Match(expr); match != nil {
-				cronExpr, err := TranslateRule(&rule, match, rules.Dictionaries)
+				cronExpr, err := rule.Translate(match, rules.Dictionaries)
+				if errors.Is(err, R.ErrUnsupported) {
+					return "", rule, rules, err
+				}
 				if err != nil {
 					continue
 				}
-				return cronExpr, nil
+				return cronExpr, rule, rules, nil
 			}
 		}
 	}
 
-	return "", fmt.Errorf("unsupported expression format: %s", expression)
+	return "", nil, nil, fmt.Errorf("%w: %s", R.ErrNoMatch, expression)
+}
+
+// timezones returns the time zone phrases of the current language, or of all
+// languages when auto-detecting
+func (m *HumanCronMapper) timezones(autoDetect bool) map[string]string {
+	if !autoDetect {
+		if m.currentRules == nil {
+			return nil
+		}
+		return m.currentRules.Timezones
+	}
+
+	phrases := make(map[string]string)
+	for _, rules := range m.allRules {
+		for phrase, zone := range rules.Timezones {
+			phrases[phrase] = zone
+		}
+	}
+	return phrases
 }
 
 // GetSupportedLanguages returns a list of supported languages
@@ -103,6 +185,28 @@ func (m *HumanCronMapper) AddRulesFromFile(filePath string) error {
 		return err
 	}
 
-	m.allRules[rules.Language] = rules
+	m.setRules(rules)
 	return nil
 }
+
+// AddRules adds rules built in Go code, replacing rules of the same language
+func (m *HumanCronMapper) AddRules(rules *R.Rules) error {
+	if rules == nil {
+		return fmt.Errorf("rules cannot be nil")
+	}
+	if err := rules.Compile(); err != nil {
+		return err
+	}
+
+	m.setRules(rules)
+	return nil
+}
+
+// setRules registers rules for their language and keeps the current language in sync
+func (m *HumanCronMapper) setRules(rules *R.Rules) {
+	if m.currentRules == nil || m.currentRules.Language == rules.Language {
+		m.currentRules = rules
+	}
+	m.allRules[rules.Language] = rules
+	m.updateVersion()
+}
@@ -0,0 +1,23 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+func TestConvertUnsupported(t *testing.T) {
+	c, err := New("./rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A looser rule matches "every monday", the nearest day must not be dropped
+	if got, err := c.Convert("every monday nearest the 15th"); !errors.Is(err, rules.ErrUnsupported) {
+		t.Errorf("Convert() = %q, %v, want %v", got, err, rules.ErrUnsupported)
+	}
+	if got, err := c.AutoDetect("каждую пятницу ближайшую к 1 числу"); !errors.Is(err, rules.ErrUnsupported) {
+		t.Errorf("AutoDetect() = %q, %v, want %v", got, err, rules.ErrUnsupported)
+	}
+}
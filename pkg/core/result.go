@@ -0,0 +1,175 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	R "github.com/flaticols/cronscribe/pkg/core/rules"
+	"github.com/flaticols/cronscribe/pkg/core/schedule"
+)
+
+// WarningCode identifies the kind of a conversion warning
+type WarningCode string
+
+const (
+	// WarningDayShift is reported when moving a schedule to the target time zone changed its day
+	WarningDayShift WarningCode = "day_shift"
+	// WarningOffsetVaries is reported when the offset between the source and target
+	// time zones changes during the year, so a shifted schedule drifts by an hour
+	WarningOffsetVaries WarningCode = "offset_varies"
+	// WarningDSTGap is reported when a scheduled local time does not exist on
+	// the day clocks move forward
+	WarningDSTGap WarningCode = "dst_gap"
+	// WarningDSTOverlap is reported when a scheduled local time happens twice
+	// on the day clocks move back
+	WarningDSTOverlap WarningCode = "dst_overlap"
+)
+
+// Warning is a non-fatal remark about a conversion
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+// Result is the detailed outcome of a conversion
+type Result struct {
+	// Expression is the cron expression rendered in the configured dialect
+	Expression string
+	// Language of the rules that matched the input
+	Language string
+	// Rule is the name of the rule that matched the input
+	Rule string
+	// Location is the time zone the expression is meant to run in, nil when unspecified
+	Location *time.Location
+	Warnings []Warning
+	// Quality tells how much of the input the rule accounted for. Inputs
+	// written in cron syntax have full quality.
+	Quality R.MatchQuality
+	// Syntax tells whether the input was natural text or already a cron
+	// expression or macro
+	Syntax Syntax
+	// Interval is the interval of an @every macro, zero for other schedules
+	Interval time.Duration
+	// Description is the schedule described in words, set with WithDescription
+	Description string
+
+	schedule *cron.Expression
+	policy   cron.DSTPolicy
+}
+
+// Next returns the next time after the given instant the schedule fires, in
+// the result's location and following the configured DST policy. @every
+// macros fire their interval after the given instant. It returns the zero time
+// when the schedule never fires, or fires only when the scheduler starts.
+func (r *Result) Next(after time.Time) time.Time {
+	if r.Interval > 0 {
+		return after.Add(r.Interval)
+	}
+	if r.schedule == nil {
+		return time.Time{}
+	}
+	return r.schedule.Next(after, r.policy)
+}
+
+// Schedule returns the typed fields of the expression, nil when the result
+// has no expression. Changing it does not change the result.
+func (r *Result) Schedule() *schedule.Schedule {
+	if r.schedule == nil {
+		return nil
+	}
+	s, err := schedule.FromExpression(r.schedule)
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// addWarning appends a warning to the result
+func (r *Result) addWarning(code WarningCode, message string) {
+	r.Warnings = append(r.Warnings, Warning{Code: code, Message: message})
+}
+
+// resultJSON is the encoded form of a Result
+type resultJSON struct {
+	Expression  string         `json:"expression"`
+	Language    string         `json:"language"`
+	Rule        string         `json:"rule"`
+	Syntax      Syntax         `json:"syntax,omitempty"`
+	Location    string         `json:"location,omitempty"`
+	Warnings    []Warning      `json:"warnings,omitempty"`
+	Fields      []string       `json:"fields,omitempty"`
+	Second      string         `json:"second,omitempty"`
+	Year        string         `json:"year,omitempty"`
+	Interval    string         `json:"interval,omitempty"`
+	Description string         `json:"description,omitempty"`
+	DSTPolicy   string         `json:"dst_policy"`
+	Quality     R.MatchQuality `json:"quality"`
+}
+
+// MarshalJSON encodes the result along with its schedule, so a decoded result
+// still answers Next
+func (r *Result) MarshalJSON() ([]byte, error) {
+	v := resultJSON{
+		Expression:  r.Expression,
+		Language:    r.Language,
+		Rule:        r.Rule,
+		Syntax:      r.Syntax,
+		Warnings:    r.Warnings,
+		Description: r.Description,
+		DSTPolicy:   r.policy.String(),
+		Quality:     r.Quality,
+	}
+	if r.Location != nil {
+		v.Location = r.Location.String()
+	}
+	if r.schedule != nil {
+		v.Fields = r.schedule.Fields[:]
+		v.Second = r.schedule.Second
+		v.Year = r.schedule.Year
+	}
+	if r.Interval > 0 {
+		v.Interval = r.Interval.String()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a result encoded with MarshalJSON
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var v resultJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	policy, err := cron.ParseDSTPolicy(v.DSTPolicy)
+	if err != nil {
+		return err
+	}
+
+	*r = Result{
+		Expression:  v.Expression,
+		Language:    v.Language,
+		Rule:        v.Rule,
+		Syntax:      v.Syntax,
+		Warnings:    v.Warnings,
+		Description: v.Description,
+		Quality:     v.Quality,
+		policy:      policy,
+	}
+	if v.Location != "" {
+		if r.Location, err = time.LoadLocation(v.Location); err != nil {
+			return fmt.Errorf("unknown time zone %s: %w", v.Location, err)
+		}
+	}
+	if v.Interval != "" {
+		if r.Interval, err = time.ParseDuration(v.Interval); err != nil {
+			return fmt.Errorf("invalid interval %s: %w", v.Interval, err)
+		}
+	}
+	if len(v.Fields) == len(cron.Expression{}.Fields) {
+		r.schedule = &cron.Expression{Second: v.Second, Year: v.Year, Location: r.Location}
+		copy(r.schedule.Fields[:], v.Fields)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package rules
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// Report is the result of analysing a rule set
+type Report struct {
+	Language string `json:"language"`
+	// Overlaps lists examples of one rule that are also matched by another rule
+	Overlaps []Overlap `json:"overlaps"`
+	// UnreachableRules lists rules whose examples are all taken by earlier rules
+	UnreachableRules []string `json:"unreachable_rules"`
+	// UntestedRules lists rules without examples, they cannot be analysed for overlaps
+	UntestedRules []string `json:"untested_rules"`
+	// UnusedDictionaryEntries lists entries no rule pattern can produce
+	UnusedDictionaryEntries []DictionaryEntry `json:"unused_dictionary_entries"`
+	// UnusedVariables lists captured variables that neither a format nor a condition refers to
+	UnusedVariables []RuleVariable `json:"unused_variables"`
+}
+
+// Overlap is an example of Rule that is matched by Other as well
+type Overlap struct {
+	Rule  string `json:"rule"`
+	Other string `json:"other"`
+	Input string `json:"input"`
+	// Shadowed is set when Other comes first in the rule set, so it wins the match
+	Shadowed bool `json:"shadowed"`
+}
+
+// DictionaryEntry identifies an entry of a dictionary
+type DictionaryEntry struct {
+	Dictionary string `json:"dictionary"`
+	Key        string `json:"key"`
+}
+
+// RuleVariable identifies a variable of a rule
+type RuleVariable struct {
+	Rule     string `json:"rule"`
+	Variable string `json:"variable"`
+}
+
+// HasShadowing reports whether an example of some rule is taken by an earlier rule
+func (r Report) HasShadowing() bool {
+	for _, overlap := range r.Overlaps {
+		if overlap.Shadowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Analyze checks a compiled rule set for overlapping and unreachable rules,
+// unused dictionary entries and unused variables
+func Analyze(rules *Rules) Report {
+	report := Report{
+		Language:                rules.Language,
+		Overlaps:                []Overlap{},
+		UnreachableRules:        []string{},
+		UntestedRules:           []string{},
+		UnusedDictionaryEntries: []DictionaryEntry{},
+		UnusedVariables:         []RuleVariable{},
+	}
+
+	analyzeOverlaps(rules, &report)
+	analyzeDictionaries(rules, &report)
+	analyzeVariables(rules, &report)
+
+	return report
+}
+
+// analyzeOverlaps runs the examples of every rule against every other rule
+func analyzeOverlaps(rules *Rules, report *Report) {
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		if len(rule.Examples) == 0 {
+			report.UntestedRules = append(report.UntestedRules, rule.Name)
+			continue
+		}
+
+		matched, shadowed := 0, 0
+		for _, example := range rule.Examples {
+			input := strings.ToLower(strings.TrimSpace(example.Input))
+			if rule.Match(input) == nil {
+				// Examples expected to fail usually match nothing at all
+				continue
+			}
+			matched++
+
+			isShadowed := false
+			for j := range rules.Rules {
+				other := &rules.Rules[j]
+				if i == j || other.Match(input) == nil {
+					continue
+				}
+
+				report.Overlaps = append(report.Overlaps, Overlap{
+					Rule:     rule.Name,
+					Other:    other.Name,
+					Input:    example.Input,
+					Shadowed: j < i,
+				})
+				isShadowed = isShadowed || j < i
+			}
+
+			if isShadowed {
+				shadowed++
+			}
+		}
+
+		if shadowed > 0 && shadowed == matched {
+			report.UnreachableRules = append(report.UnreachableRules, rule.Name)
+		}
+	}
+}
+
+// analyzeDictionaries finds dictionary entries that no referencing rule can capture
+func analyzeDictionaries(rules *Rules, report *Report) {
+	dictNames := make([]string, 0, len(rules.Dictionaries))
+	for name := range rules.Dictionaries {
+		dictNames = append(dictNames, name)
+	}
+	sort.Strings(dictNames)
+
+	for _, dictName := range dictNames {
+		keys := make([]string, 0, len(rules.Dictionaries[dictName]))
+		for key := range rules.Dictionaries[dictName] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if !dictionaryEntryUsed(rules, dictName, key) {
+				report.UnusedDictionaryEntries = append(report.UnusedDictionaryEntries, DictionaryEntry{
+					Dictionary: dictName,
+					Key:        key,
+				})
+			}
+		}
+	}
+}
+
+// dictionaryEntryUsed reports whether any rule looking up the dictionary can
+// capture the key or produce it with a transformation
+func dictionaryEntryUsed(rules *Rules, dictName, key string) bool {
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		for variable, name := range rule.Dictionaries {
+			if name != dictName {
+				continue
+			}
+
+			for _, t := range rule.Transformations[variable] {
+				if strings.Trim(strings.TrimSpace(t.Operation), `'"`) == key {
+					return true
+				}
+			}
+
+			group, ok := captureGroup(rule.Pattern, rule.Variables[variable])
+			if ok && group.MatchString(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// captureGroup compiles the sub-expression of a capture group as a whole-string matcher
+func captureGroup(pattern string, index int) (*regexp.Regexp, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+
+	var found *syntax.Regexp
+	var walk func(*syntax.Regexp)
+	walk = func(node *syntax.Regexp) {
+		if found != nil {
+			return
+		}
+		if node.Op == syntax.OpCapture && node.Cap == index {
+			found = node.Sub[0]
+			return
+		}
+		for _, sub := range node.Sub {
+			walk(sub)
+		}
+	}
+	walk(re)
+
+	if found == nil {
+		return nil, false
+	}
+
+	group, err := regexp.Compile(`^(?:` + found.String() + `)$`)
+	if err != nil {
+		return nil, false
+	}
+	return group, true
+}
+
+// analyzeVariables finds captured variables no format or condition refers to
+func analyzeVariables(rules *Rules, report *Report) {
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+
+		names := make([]string, 0, len(rule.Variables))
+		for name := range rule.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		formats := []string{rule.Format}
+		var conditions []string
+		for _, specialCase := range rule.SpecialCases {
+			formats = append(formats, specialCase.Format)
+			conditions = append(conditions, specialCase.Condition)
+		}
+		for _, transformations := range rule.Transformations {
+			for _, t := range transformations {
+				conditions = append(conditions, t.Condition, t.Operation)
+			}
+		}
+
+		referenced := referencedVariables(formats, names)
+		for _, condition := range conditions {
+			tokens, err := tokenize(condition)
+			if err != nil {
+				continue
+			}
+			for _, token := range tokens {
+				referenced[token] = true
+			}
+		}
+
+		for _, name := range names {
+			if !referenced[name] {
+				report.UnusedVariables = append(report.UnusedVariables, RuleVariable{
+					Rule:     rule.Name,
+					Variable: name,
+				})
+			}
+		}
+	}
+}
+
+// referencedVariables returns the variables that appear as %name in the formats.
+// Longer names are consumed first, the same way formats are applied.
+func referencedVariables(formats, names []string) map[string]bool {
+	sorted := append([]string(nil), names...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	referenced := make(map[string]bool)
+	for _, format := range formats {
+		for _, name := range sorted {
+			if strings.Contains(format, "%"+name) {
+				referenced[name] = true
+				format = strings.ReplaceAll(format, "%"+name, "")
+			}
+		}
+	}
+	return referenced
+}
@@ -0,0 +1,54 @@
+package rules
+
+import "testing"
+
+func TestAnalyze(t *testing.T) {
+	rules, err := NewRules("en").
+		Add(
+			NewRule("every_weekday").
+				Pattern(`every\s+(monday|friday)`).
+				Var("weekday", 1).
+				Dict("weekday", "weekdays").
+				Format("0 0 * * %weekday").
+				Example("every monday", "0 0 * * 1"),
+			NewRule("weekday_at_hour").
+				Pattern(`every\s+(monday|friday)\s+at\s+(\d+)(am|pm)?`).
+				Var("weekday", 1).
+				Var("hour", 2).
+				Var("ampm", 3).
+				Dict("weekday", "weekdays").
+				Format("0 %hour * * %weekday").
+				Example("every friday at 9", "0 9 * * 5").
+				// Matched by no rule, it does not make the rule reachable
+				ExampleError("every sunday at 9"),
+			NewRule("hourly").
+				Pattern(`every\s+hour`).
+				Format("0 * * * *"),
+		).
+		Dictionary("weekdays", map[string]string{"monday": "1", "friday": "5", "sunday": "0"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	report := Analyze(rules)
+
+	if !report.HasShadowing() || len(report.Overlaps) != 1 {
+		t.Fatalf("Overlaps = %+v, want weekday_at_hour shadowed by every_weekday", report.Overlaps)
+	}
+	if o := report.Overlaps[0]; o.Rule != "weekday_at_hour" || o.Other != "every_weekday" || !o.Shadowed {
+		t.Errorf("Overlaps[0] = %+v", o)
+	}
+	if len(report.UnreachableRules) != 1 || report.UnreachableRules[0] != "weekday_at_hour" {
+		t.Errorf("UnreachableRules = %v", report.UnreachableRules)
+	}
+	if len(report.UntestedRules) != 1 || report.UntestedRules[0] != "hourly" {
+		t.Errorf("UntestedRules = %v", report.UntestedRules)
+	}
+	if want := (DictionaryEntry{Dictionary: "weekdays", Key: "sunday"}); len(report.UnusedDictionaryEntries) != 1 || report.UnusedDictionaryEntries[0] != want {
+		t.Errorf("UnusedDictionaryEntries = %v", report.UnusedDictionaryEntries)
+	}
+	if want := (RuleVariable{Rule: "weekday_at_hour", Variable: "ampm"}); len(report.UnusedVariables) != 1 || report.UnusedVariables[0] != want {
+		t.Errorf("UnusedVariables = %v", report.UnusedVariables)
+	}
+}
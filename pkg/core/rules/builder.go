@@ -0,0 +1,142 @@
+package rules
+
+import "maps"
+
+// RuleBuilder assembles a Rule in Go code instead of YAML
+type RuleBuilder struct {
+	rule Rule
+}
+
+// NewRule starts building a rule with the given name
+func NewRule(name string) *RuleBuilder {
+	return &RuleBuilder{rule: Rule{Name: name}}
+}
+
+// Pattern sets the regular expression the rule matches
+func (b *RuleBuilder) Pattern(pattern string) *RuleBuilder {
+	b.rule.Pattern = pattern
+	return b
+}
+
+// Var maps a regex capture group to a named variable
+func (b *RuleBuilder) Var(name string, group int) *RuleBuilder {
+	if b.rule.Variables == nil {
+		b.rule.Variables = make(map[string]int)
+	}
+	b.rule.Variables[name] = group
+	return b
+}
+
+// Dict makes the variable to be looked up in the named dictionary
+func (b *RuleBuilder) Dict(variable, dictionary string) *RuleBuilder {
+	if b.rule.Dictionaries == nil {
+		b.rule.Dictionaries = make(map[string]string)
+	}
+	b.rule.Dictionaries[variable] = dictionary
+	return b
+}
+
+// Format sets the cron format template of the rule
+func (b *RuleBuilder) Format(format string) *RuleBuilder {
+	b.rule.Format = format
+	return b
+}
+
+// Unsupported marks the phrasing the rule matches as one cron cannot express,
+// so conversions it matches fail with ErrUnsupported instead of reaching
+// a looser rule
+func (b *RuleBuilder) Unsupported(reason string) *RuleBuilder {
+	b.rule.Unsupported = reason
+	return b
+}
+
+// Default sets the fallback value for an optional variable
+func (b *RuleBuilder) Default(variable, value string) *RuleBuilder {
+	if b.rule.DefaultValues == nil {
+		b.rule.DefaultValues = make(map[string]string)
+	}
+	b.rule.DefaultValues[variable] = value
+	return b
+}
+
+// Transform adds a conditional transformation of a variable
+func (b *RuleBuilder) Transform(variable, condition, operation string) *RuleBuilder {
+	if b.rule.Transformations == nil {
+		b.rule.Transformations = make(map[string][]Transformation)
+	}
+	b.rule.Transformations[variable] = append(b.rule.Transformations[variable], Transformation{
+		Condition: condition,
+		Operation: operation,
+	})
+	return b
+}
+
+// When adds a special case that replaces the format if the condition holds
+func (b *RuleBuilder) When(condition, format string) *RuleBuilder {
+	b.rule.SpecialCases = append(b.rule.SpecialCases, SpecialCase{
+		Condition: condition,
+		Format:    format,
+	})
+	return b
+}
+
+// Example adds an input with the cron expression it must convert to
+func (b *RuleBuilder) Example(input, cron string) *RuleBuilder {
+	b.rule.Examples = append(b.rule.Examples, Example{Input: input, Cron: cron})
+	return b
+}
+
+// ExampleError adds an input whose conversion must fail
+func (b *RuleBuilder) ExampleError(input string) *RuleBuilder {
+	b.rule.Examples = append(b.rule.Examples, Example{Input: input, Error: true})
+	return b
+}
+
+// Rule returns the assembled rule. The rule is validated once it is part
+// of a rule set, see Rules.Compile.
+func (b *RuleBuilder) Rule() Rule {
+	return b.rule
+}
+
+// RulesBuilder assembles a rule set for one language in Go code
+type RulesBuilder struct {
+	rules Rules
+}
+
+// NewRules starts building a rule set for the given language
+func NewRules(language string) *RulesBuilder {
+	return &RulesBuilder{rules: Rules{
+		Language:     language,
+		Dictionaries: make(map[string]map[string]string),
+	}}
+}
+
+// Add appends rules to the set, first added rules are matched first
+func (b *RulesBuilder) Add(rules ...*RuleBuilder) *RulesBuilder {
+	for _, rule := range rules {
+		b.rules.Rules = append(b.rules.Rules, rule.Rule())
+	}
+	return b
+}
+
+// Dictionary adds a dictionary to the set
+func (b *RulesBuilder) Dictionary(name string, entries map[string]string) *RulesBuilder {
+	b.rules.Dictionaries[name] = entries
+	return b
+}
+
+// Build validates and compiles the rule set the same way LoadRulesFromFile does.
+// The set owns copies of the dictionaries, so later calls on the builder do
+// not change sets already built.
+func (b *RulesBuilder) Build() (*Rules, error) {
+	rules := b.rules
+	rules.Rules = append([]Rule(nil), b.rules.Rules...)
+	rules.Dictionaries = make(map[string]map[string]string, len(b.rules.Dictionaries))
+	for name, entries := range b.rules.Dictionaries {
+		rules.Dictionaries[name] = maps.Clone(entries)
+	}
+	if err := rules.Compile(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
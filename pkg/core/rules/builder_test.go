@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRulesBuilder(t *testing.T) {
+	rules, err := NewRules("en").
+		Add(NewRule("weekday_at_hour").
+			Pattern(`(?i)on\s+(monday|friday)\s+at\s+(\d+)`).
+			Var("weekday", 1).
+			Var("hour", 2).
+			Dict("weekday", "weekdays").
+			Format("0 %hour * * %weekday")).
+		Dictionary("weekdays", map[string]string{"monday": "1", "friday": "5"}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	match := rules.Rules[0].Match("on friday at 7")
+	if len(match) != 3 || match[1] != "friday" || match[2] != "7" {
+		t.Fatalf("Match() = %v", match)
+	}
+}
+
+func TestRulesBuilderCopiesDictionaries(t *testing.T) {
+	weekdays := map[string]string{"monday": "1"}
+	builder := NewRules("en").
+		Add(NewRule("weekday").Pattern(`on (\w+)`).Var("weekday", 1).Dict("weekday", "weekdays").Format("0 0 * * %weekday")).
+		Dictionary("weekdays", weekdays)
+	rules, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	builder.Dictionary("weekdays", map[string]string{"monday": "7"})
+	weekdays["friday"] = "5"
+	if got, _, err := rules.Convert("on monday"); err != nil || got != "0 0 * * 1" {
+		t.Errorf("Convert() = %q, %v, want %q", got, err, "0 0 * * 1")
+	}
+	if _, ok := rules.Dictionaries["weekdays"]["friday"]; ok {
+		t.Error("built set shares the dictionary map passed to the builder")
+	}
+}
+
+func TestRulesBuilderUnsupported(t *testing.T) {
+	rules, err := NewRules("en").
+		Add(
+			NewRule("fortnightly").Pattern(`fortnightly`).Unsupported("cron has no fortnights"),
+			NewRule("weekly").Pattern(`on fridays`).Format("0 0 * * 5"),
+		).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if _, rule, err := rules.Convert("fortnightly on fridays"); !errors.Is(err, ErrUnsupported) || rule == nil || rule.Name != "fortnightly" {
+		t.Errorf("Convert() = %v, %v, want ErrUnsupported from fortnightly", rule, err)
+	}
+}
+
+func TestRulesBuilderValidation(t *testing.T) {
+	tests := map[string]*RulesBuilder{
+		"bad pattern":     NewRules("en").Add(NewRule("r").Pattern(`(`).Format("* * * * *")),
+		"group overflow":  NewRules("en").Add(NewRule("r").Pattern(`every (\d+)`).Var("n", 2).Format("*/%n * * * *")),
+		"missing dict":    NewRules("en").Add(NewRule("r").Pattern(`every (\w+)`).Var("d", 1).Dict("d", "days").Format("0 0 * * %d")),
+		"empty format":    NewRules("en").Add(NewRule("r").Pattern(`hourly`)),
+		"duplicate names": NewRules("en").Add(NewRule("r").Pattern(`a`).Format("* * * * *"), NewRule("r").Pattern(`b`).Format("* * * * *")),
+		"no language":     NewRules("").Add(NewRule("r").Pattern(`a`).Format("* * * * *")),
+	}
+
+	for name, builder := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := builder.Build(); err == nil {
+				t.Fatal("Build() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExampleResult is the outcome of running one rule example
+type ExampleResult struct {
+	Language string
+	// Rule is the name of the rule the example belongs to
+	Rule    string
+	Example Example
+	// MatchedRule is the name of the rule that actually matched the input
+	MatchedRule string
+	Got         string
+	Err         error
+	Passed      bool
+}
+
+// Diff describes how the result differs from the expected one
+func (r ExampleResult) Diff() string {
+	if r.Passed {
+		return ""
+	}
+
+	var b strings.Builder
+	if r.Example.Error {
+		b.WriteString("- want: error\n")
+	} else {
+		fmt.Fprintf(&b, "- want: %s\n", r.Example.Cron)
+	}
+
+	if r.Err != nil {
+		fmt.Fprintf(&b, "+ got:  error: %v\n", r.Err)
+	} else {
+		fmt.Fprintf(&b, "+ got:  %s\n", r.Got)
+	}
+
+	if r.MatchedRule != "" && r.MatchedRule != r.Rule {
+		fmt.Fprintf(&b, "  matched by rule %s\n", r.MatchedRule)
+	}
+
+	return b.String()
+}
+
+// RunExamples converts the examples of every rule with the whole rule set,
+// the same way a mapper would, and compares the results with the expectations
+func RunExamples(rules *Rules) []ExampleResult {
+	var results []ExampleResult
+
+	for _, rule := range rules.Rules {
+		for _, example := range rule.Examples {
+			result := ExampleResult{
+				Language: rules.Language,
+				Rule:     rule.Name,
+				Example:  example,
+			}
+
+			var matched *Rule
+			result.Got, matched, result.Err = rules.Convert(example.Input)
+			if matched != nil {
+				result.MatchedRule = matched.Name
+			}
+
+			if example.Error {
+				result.Passed = result.Err != nil
+			} else {
+				result.Passed = result.Err == nil && result.Got == example.Cron
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
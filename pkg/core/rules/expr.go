@@ -0,0 +1,272 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// exprParser evaluates the small expression language used in rule
+// conditions and operations:
+//
+//	condition  = and { "||" and }
+//	and        = comparison { "&&" comparison }
+//	comparison = "(" condition ")" | value [ ("=="|"!="|"<"|">"|"<="|">=") value ]
+//	operation  = value { ("+"|"-") value }
+//	value      = 'text' | "text" | number | variable
+//
+// Variables are resolved from the map, unknown identifiers evaluate to themselves.
+type exprParser struct {
+	tokens    []string
+	pos       int
+	variables map[string]string
+}
+
+func newExprParser(input string, variables map[string]string) (*exprParser, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	return &exprParser{tokens: tokens, variables: variables}, nil
+}
+
+// evalCondition evaluates a condition against the variables
+func evalCondition(condition string, variables map[string]string) (bool, error) {
+	p, err := newExprParser(condition, variables)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := p.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if !p.done() {
+		return false, fmt.Errorf("unexpected %q in condition %q", p.peek(), condition)
+	}
+	return result, nil
+}
+
+// evalOperation evaluates an operation against the variables
+func evalOperation(operation string, variables map[string]string) (string, error) {
+	p, err := newExprParser(operation, variables)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := p.parseValue()
+	if err != nil {
+		return "", err
+	}
+
+	for !p.done() {
+		op := p.next()
+		if op != "+" && op != "-" {
+			return "", fmt.Errorf("unexpected %q in operation %q", op, operation)
+		}
+
+		right, err := p.parseValue()
+		if err != nil {
+			return "", err
+		}
+
+		l, errL := strconv.Atoi(result)
+		r, errR := strconv.Atoi(right)
+		if errL != nil || errR != nil {
+			return "", fmt.Errorf("non-numeric operands %q %s %q in operation %q", result, op, right, operation)
+		}
+
+		if op == "+" {
+			result = strconv.Itoa(l + r)
+		} else {
+			result = strconv.Itoa(l - r)
+		}
+	}
+
+	return result, nil
+}
+
+func (p *exprParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+		result = result || right
+	}
+	return result, nil
+}
+
+func (p *exprParser) parseAnd() (bool, error) {
+	result, err := p.parseComparison()
+	if err != nil {
+		return false, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return false, err
+		}
+		result = result && right
+	}
+	return result, nil
+}
+
+func (p *exprParser) parseComparison() (bool, error) {
+	if p.peek() == "(" {
+		p.next()
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if p.next() != ")" {
+			return false, fmt.Errorf("missing closing parenthesis")
+		}
+		return result, nil
+	}
+
+	left, err := p.parseValue()
+	if err != nil {
+		return false, err
+	}
+
+	op := p.peek()
+	switch op {
+	case "==", "!=", "<", ">", "<=", ">=":
+		p.next()
+	default:
+		// A bare value is true when it is set
+		return left != "" && left != "0" && left != "false", nil
+	}
+
+	right, err := p.parseValue()
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	l, errL := strconv.Atoi(left)
+	r, errR := strconv.Atoi(right)
+	if errL != nil || errR != nil {
+		return false, nil
+	}
+
+	switch op {
+	case "<":
+		return l < r, nil
+	case ">":
+		return l > r, nil
+	case "<=":
+		return l <= r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+func (p *exprParser) parseValue() (string, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return "", fmt.Errorf("unexpected end of expression")
+	case token[0] == '\'' || token[0] == '"':
+		return token[1 : len(token)-1], nil
+	case unicode.IsDigit(rune(token[0])):
+		return token, nil
+	case isIdentStart([]rune(token)[0]):
+		if value, ok := p.variables[token]; ok {
+			return value, nil
+		}
+		return token, nil
+	}
+	return "", fmt.Errorf("unexpected %q", token)
+}
+
+func (p *exprParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() string {
+	token := p.peek()
+	if token != "" {
+		p.pos++
+	}
+	return token
+}
+
+func (p *exprParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// tokenize splits an expression into literals, identifiers and operators
+func tokenize(input string) ([]string, error) {
+	var tokens []string
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != c {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string in %q", input)
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case unicode.IsDigit(c):
+			end := i
+			for end < len(runes) && unicode.IsDigit(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		case isIdentStart(c):
+			end := i
+			for end < len(runes) && (isIdentStart(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			rest := string(runes[i:])
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "+", "-", "(", ")"} {
+				if strings.HasPrefix(rest, candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q in %q", c, input)
+			}
+			tokens = append(tokens, op)
+			i += len(op)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isIdentStart(c rune) bool {
+	return unicode.IsLetter(c) || c == '_'
+}
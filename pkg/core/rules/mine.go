@@ -0,0 +1,401 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Observation is a phrase together with the cron expression it should convert
+// to, typically an answer recorded from an AI fallback
+type Observation struct {
+	Input    string
+	Language string
+	Cron     string
+}
+
+// Candidate is a rule proposed from observations that share a phrase structure
+type Candidate struct {
+	Language string
+	Rule     Rule
+	// Support is the number of observations the rule reproduces
+	Support int
+}
+
+// Mine groups observations whose phrases differ only in numbers and dictionary
+// words, such as "every 5 minutes" and "every 10 minutes", and proposes a rule
+// for every group: a pattern capturing the varying words, variables and a
+// format explaining every recorded expression. The observations are attached
+// as examples, and a candidate is only proposed when it reproduces all of them.
+// Dictionaries are taken from the known rule sets of each language, and phrases
+// these rules already convert are ignored. Groups smaller than minSupport are
+// skipped.
+func Mine(observations []Observation, known map[string]*Rules, minSupport int) []Candidate {
+	clusters := make(map[string]*cluster)
+	var order []string
+
+	for _, o := range observations {
+		if o.Language == "" || strings.Contains(o.Cron, "=") || len(strings.Fields(o.Cron)) != 5 {
+			continue
+		}
+
+		var dictionaries map[string]map[string]string
+		if rules, ok := known[o.Language]; ok {
+			// Phrases the rules already cover need no new rule
+			if _, _, err := rules.Convert(o.Input); err == nil {
+				continue
+			}
+			dictionaries = rules.Dictionaries
+		}
+
+		tokens := tokenizePhrase(strings.ToLower(strings.TrimSpace(o.Input)), dictionaries)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		key := o.Language + "\x00" + clusterKey(tokens)
+		c, ok := clusters[key]
+		if !ok {
+			c = &cluster{language: o.Language, template: tokens, dictionaries: dictionaries}
+			clusters[key] = c
+			order = append(order, key)
+		}
+		c.add(tokens, o)
+	}
+
+	var candidates []Candidate
+	names := make(map[string]int)
+	for _, key := range order {
+		c := clusters[key]
+		if c.support < minSupport {
+			continue
+		}
+
+		rule, ok := c.propose()
+		if !ok {
+			continue
+		}
+
+		// Keep names unique within a language
+		base := rule.Name
+		names[c.language+base]++
+		if n := names[c.language+base]; n > 1 {
+			rule.Name = fmt.Sprintf("%s_%d", base, n)
+		}
+
+		candidates = append(candidates, Candidate{Language: c.language, Rule: rule, Support: c.support})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Language != candidates[j].Language {
+			return candidates[i].Language < candidates[j].Language
+		}
+		return candidates[i].Support > candidates[j].Support
+	})
+	return candidates
+}
+
+// tokenKind classifies the words of a phrase
+type tokenKind int
+
+const (
+	literalToken tokenKind = iota
+	numberToken
+	dictionaryToken
+)
+
+// phraseToken is a word, a number or a punctuation mark of a phrase
+type phraseToken struct {
+	text       string
+	kind       tokenKind
+	dictionary string
+	// value is what the token contributes to a cron expression: the number
+	// itself or the dictionary value
+	value string
+	// spaced reports whether whitespace precedes the token
+	spaced bool
+}
+
+var phraseWord = regexp.MustCompile(`\d+|\p{L}+|[^\s\d\p{L}]`)
+
+// tokenizePhrase splits a phrase into tokens, classifying numbers and words
+// found in a dictionary
+func tokenizePhrase(phrase string, dictionaries map[string]map[string]string) []phraseToken {
+	names := make([]string, 0, len(dictionaries))
+	for name := range dictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tokens []phraseToken
+	end := 0
+	for _, loc := range phraseWord.FindAllStringIndex(phrase, -1) {
+		token := phraseToken{text: phrase[loc[0]:loc[1]], spaced: loc[0] > end && len(tokens) > 0}
+		end = loc[1]
+
+		switch {
+		case unicode.IsDigit(rune(token.text[0])):
+			token.kind, token.value = numberToken, token.text
+		default:
+			for _, name := range names {
+				if value, ok := dictionaries[name][token.text]; ok {
+					token.kind, token.dictionary, token.value = dictionaryToken, name, value
+					break
+				}
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// clusterKey describes the structure of a phrase, with numbers and dictionary
+// words replaced by their class
+func clusterKey(tokens []phraseToken) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		if t.spaced {
+			b.WriteByte(' ')
+		}
+		switch t.kind {
+		case numberToken:
+			b.WriteString("\x01#")
+		case dictionaryToken:
+			b.WriteString("\x01" + t.dictionary)
+		default:
+			b.WriteString(t.text)
+		}
+		b.WriteByte('\x00')
+	}
+	return b.String()
+}
+
+// cluster collects observations sharing a phrase structure
+type cluster struct {
+	language     string
+	template     []phraseToken
+	dictionaries map[string]map[string]string
+	phrases      [][]phraseToken
+	crons        [][5]string
+	examples     []Example
+	seen         map[string]bool
+	support      int
+}
+
+func (c *cluster) add(tokens []phraseToken, o Observation) {
+	c.support++
+	input := strings.Join(strings.Fields(o.Input), " ")
+	if c.seen == nil {
+		c.seen = make(map[string]bool)
+	}
+	if c.seen[strings.ToLower(input)] {
+		return
+	}
+	c.seen[strings.ToLower(input)] = true
+
+	var fields [5]string
+	copy(fields[:], strings.Fields(o.Cron))
+	c.phrases = append(c.phrases, tokens)
+	c.crons = append(c.crons, fields)
+	c.examples = append(c.examples, Example{Input: input, Cron: strings.Join(fields[:], " ")})
+}
+
+var cronPart = regexp.MustCompile(`\d+|[^\d]+`)
+
+// propose builds a rule explaining every phrase of the cluster, and checks
+// that it reproduces all of them
+func (c *cluster) propose() (Rule, bool) {
+	used := make(map[int]bool)
+	var format []string
+
+	for f := 0; f < 5; f++ {
+		field, ok := c.explainField(f, used)
+		if !ok {
+			return Rule{}, false
+		}
+		format = append(format, field)
+	}
+
+	rule := Rule{
+		Name:     c.name(),
+		Format:   strings.Join(format, " "),
+		Examples: c.examples,
+	}
+
+	// Slots used by the format become variables, the others stay literal or
+	// match without capturing when they vary
+	var pattern strings.Builder
+	pattern.WriteString("(?i)^")
+	group := 0
+	names := make(map[string]bool)
+	for i, t := range c.template {
+		if t.spaced {
+			pattern.WriteString(`\s+`)
+		}
+
+		if t.kind == literalToken || (!used[i] && !c.varies(i)) {
+			pattern.WriteString(regexp.QuoteMeta(t.text))
+			continue
+		}
+
+		alternatives := `\d+`
+		if t.kind == dictionaryToken {
+			alternatives = dictionaryAlternatives(c.dictionaries[t.dictionary])
+		}
+		if !used[i] {
+			pattern.WriteString("(?:" + alternatives + ")")
+			continue
+		}
+
+		group++
+		pattern.WriteString("(" + alternatives + ")")
+
+		name := slotName(t, names)
+		if rule.Variables == nil {
+			rule.Variables = make(map[string]int)
+		}
+		rule.Variables[name] = group
+		if t.kind == dictionaryToken {
+			if rule.Dictionaries == nil {
+				rule.Dictionaries = make(map[string]string)
+			}
+			rule.Dictionaries[name] = t.dictionary
+		}
+		rule.Format = strings.ReplaceAll(rule.Format, slotPlaceholder(i), "%"+name)
+	}
+	pattern.WriteString("$")
+	rule.Pattern = pattern.String()
+
+	// The candidate must reproduce every recorded expression
+	set := &Rules{Language: c.language, Rules: []Rule{rule}, Dictionaries: c.dictionaries}
+	if err := set.Compile(); err != nil {
+		return Rule{}, false
+	}
+	for _, result := range RunExamples(set) {
+		if !result.Passed {
+			return Rule{}, false
+		}
+	}
+
+	rule.compiledPattern = nil
+	return rule, true
+}
+
+// explainField writes a cron field as a format. Parts that are equal in every
+// observation stay literal unless a phrase slot always holds the same value;
+// parts that vary must be explained by a slot.
+func (c *cluster) explainField(f int, used map[int]bool) (string, bool) {
+	parts := make([][]string, len(c.crons))
+	for i, fields := range c.crons {
+		parts[i] = cronPart.FindAllString(fields[f], -1)
+		if len(parts[i]) != len(parts[0]) {
+			return "", false
+		}
+	}
+
+	var b strings.Builder
+	for p := range parts[0] {
+		slot := c.findSlot(func(i int) string { return parts[i][p] })
+		if slot >= 0 {
+			used[slot] = true
+			b.WriteString(slotPlaceholder(slot))
+			continue
+		}
+
+		for i := range parts {
+			if parts[i][p] != parts[0][p] {
+				return "", false
+			}
+		}
+		b.WriteString(parts[0][p])
+	}
+	return b.String(), true
+}
+
+// findSlot returns the first number or dictionary token whose value equals the
+// given cron part in every observation, or -1
+func (c *cluster) findSlot(part func(i int) string) int {
+	for slot, t := range c.template {
+		if t.kind == literalToken {
+			continue
+		}
+
+		matches := true
+		for i, tokens := range c.phrases {
+			if tokens[slot].value != part(i) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return slot
+		}
+	}
+	return -1
+}
+
+// varies reports whether a token differs between the observations
+func (c *cluster) varies(slot int) bool {
+	for _, tokens := range c.phrases {
+		if tokens[slot].text != c.phrases[0][slot].text {
+			return true
+		}
+	}
+	return false
+}
+
+// name derives a rule name from the literal words of the phrase
+func (c *cluster) name() string {
+	words := []string{"mined"}
+	for _, t := range c.template {
+		if t.kind == dictionaryToken {
+			words = append(words, strings.TrimSuffix(t.dictionary, "s"))
+			continue
+		}
+		if t.kind == numberToken {
+			words = append(words, "n")
+			continue
+		}
+		if unicode.IsLetter([]rune(t.text)[0]) {
+			words = append(words, t.text)
+		}
+	}
+	return strings.Join(words, "_")
+}
+
+// slotPlaceholder marks a slot in a format until it has a variable name
+func slotPlaceholder(slot int) string {
+	return fmt.Sprintf("\x00%d\x00", slot)
+}
+
+// slotName picks a unique variable name for a slot
+func slotName(t phraseToken, taken map[string]bool) string {
+	base := "n"
+	if t.kind == dictionaryToken {
+		base = strings.TrimSuffix(t.dictionary, "s")
+	}
+
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	taken[name] = true
+	return name
+}
+
+// dictionaryAlternatives matches any key of a dictionary, longest keys first
+func dictionaryAlternatives(dictionary map[string]string) string {
+	keys := make([]string, 0, len(dictionary))
+	for key := range dictionary {
+		keys = append(keys, regexp.QuoteMeta(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return strings.Join(keys, "|")
+}
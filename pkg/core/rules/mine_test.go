@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"testing"
+)
+
+func TestMine(t *testing.T) {
+	known := map[string]*Rules{
+		"en": {Language: "en", Dictionaries: map[string]map[string]string{
+			"weekdays": {"monday": "1", "tuesday": "2", "friday": "5"},
+		}},
+	}
+
+	observations := []Observation{
+		{Input: "every 5 mins", Language: "en", Cron: "*/5 * * * *"},
+		{Input: "Every 20 mins", Language: "en", Cron: "*/20 * * * *"},
+		{Input: "every 5 mins", Language: "en", Cron: "*/5 * * * *"},
+		{Input: "fortnightly on monday at 9", Language: "en", Cron: "0 9 * * 1"},
+		{Input: "fortnightly on friday at 17", Language: "en", Cron: "0 17 * * 5"},
+		{Input: "at tea time", Language: "en", Cron: "0 16 * * *"},
+		{Input: "whenever 3", Language: "en", Cron: "0 0 * * *"},
+		{Input: "whenever 4", Language: "en", Cron: "0 1 * * *"},
+		{Input: "no language", Cron: "0 0 * * *"},
+	}
+
+	candidates := Mine(observations, known, 2)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", candidates)
+	}
+
+	minutes := candidates[0]
+	if minutes.Support != 3 || minutes.Rule.Format != "*/%n * * * *" || len(minutes.Rule.Examples) != 2 {
+		t.Errorf("unexpected candidate %+v", minutes)
+	}
+	if minutes.Rule.Name != "mined_every_n_mins" || minutes.Rule.Pattern != `(?i)^every\s+(\d+)\s+mins$` {
+		t.Errorf("unexpected rule %s: %s", minutes.Rule.Name, minutes.Rule.Pattern)
+	}
+
+	weekly := candidates[1]
+	if weekly.Rule.Format != "0 %n * * %weekday" || weekly.Rule.Dictionaries["weekday"] != "weekdays" {
+		t.Errorf("unexpected candidate %+v", weekly.Rule)
+	}
+
+	// The candidates convert unseen phrases of the same shape
+	set := &Rules{Language: "en", Dictionaries: known["en"].Dictionaries}
+	for _, c := range candidates {
+		set.Rules = append(set.Rules, c.Rule)
+	}
+	if err := set.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _, err := set.Convert("fortnightly on tuesday at 6"); err != nil || got != "0 6 * * 2" {
+		t.Errorf("Convert = %q, %v", got, err)
+	}
+
+	// With a support of one, single phrases become literal rules
+	var literal *Rule
+	for _, c := range Mine(observations, known, 1) {
+		if c.Rule.Name == "mined_at_tea_time" {
+			literal = &c.Rule
+		}
+	}
+	if literal == nil || literal.Pattern != `(?i)^at\s+tea\s+time$` || literal.Format != "0 16 * * *" {
+		t.Errorf("unexpected literal rule %+v", literal)
+	}
+}
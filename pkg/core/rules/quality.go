@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// MatchQuality describes how much of an input a rule accounted for. Patterns
+// are not anchored, so a rule can match a fragment and ignore the rest.
+type MatchQuality struct {
+	// Score combines the other measures into a value between 0 and 1
+	Score float64 `json:"score"`
+	// Coverage is the share of the input's words inside the match
+	Coverage float64 `json:"coverage"`
+	// Leftover are the words outside the match
+	Leftover []string `json:"leftover,omitempty"`
+	// Significant is the number of leftover words that are numbers or
+	// dictionary words, which likely carry schedule details the rule ignored
+	Significant int `json:"significant,omitempty"`
+	// Specificity is the share of the matched text spelled out literally in
+	// the pattern rather than matched by wildcards
+	Specificity float64 `json:"specificity"`
+}
+
+var qualityWord = regexp.MustCompile(`\p{L}+|\d+`)
+
+// significantPenalty is subtracted from the score for every significant leftover word
+const significantPenalty = 0.15
+
+// Quality measures how well the rule matches an expression, which is
+// normalized the way Convert does. The zero value is returned when the rule
+// does not match.
+func (r *Rule) Quality(expression string, dictionaries map[string]map[string]string) MatchQuality {
+	expr := strings.ToLower(strings.TrimSpace(expression))
+
+	if r.compiledPattern == nil {
+		if err := r.CompilePattern(); err != nil {
+			return MatchQuality{}
+		}
+	}
+	loc := r.compiledPattern.FindStringIndex(expr)
+	if loc == nil {
+		return MatchQuality{}
+	}
+
+	var q MatchQuality
+	words := qualityWord.FindAllStringIndex(expr, -1)
+	covered := 0
+	for _, w := range words {
+		if w[0] >= loc[0] && w[1] <= loc[1] {
+			covered++
+			continue
+		}
+
+		word := expr[w[0]:w[1]]
+		q.Leftover = append(q.Leftover, word)
+		if unicode.IsDigit(rune(word[0])) || inDictionary(word, dictionaries) {
+			q.Significant++
+		}
+	}
+	q.Coverage = 1
+	if len(words) > 0 {
+		q.Coverage = float64(covered) / float64(len(words))
+	}
+
+	if matched := nonSpaceLength(expr[loc[0]:loc[1]]); matched > 0 {
+		q.Specificity = math.Min(1, float64(literalLength(r.Pattern))/float64(matched))
+	}
+
+	q.Score = q.Coverage*(0.8+0.2*q.Specificity) - significantPenalty*float64(q.Significant)
+	q.Score = math.Round(math.Max(0, math.Min(1, q.Score))*1000) / 1000
+	return q
+}
+
+func inDictionary(word string, dictionaries map[string]map[string]string) bool {
+	for _, dictionary := range dictionaries {
+		if _, ok := dictionary[word]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func nonSpaceLength(s string) int {
+	n := 0
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// literalLength counts the non-space characters a pattern always spells out
+// literally. Of alternatives, the shortest one counts.
+func literalLength(pattern string) int {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0
+	}
+	return literalRunes(re.Simplify())
+}
+
+func literalRunes(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return nonSpaceLength(string(re.Rune))
+	case syntax.OpCapture, syntax.OpPlus:
+		return literalRunes(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return re.Min * literalRunes(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		n := 0
+		for _, sub := range re.Sub {
+			n += literalRunes(sub)
+		}
+		return n
+	case syntax.OpAlternate:
+		n := -1
+		for _, sub := range re.Sub {
+			if l := literalRunes(sub); n < 0 || l < n {
+				n = l
+			}
+		}
+		return max(n, 0)
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuleQuality(t *testing.T) {
+	rules, err := NewRules("en").
+		Add(
+			NewRule("every_n_minutes").
+				Pattern(`every\s+(\d+)\s+minutes`).
+				Var("n", 1).
+				Format("*/%n * * * *"),
+			NewRule("anything_daily").
+				Pattern(`.*daily.*`).
+				Format("0 0 * * *"),
+		).
+		Dictionary("weekdays", map[string]string{"monday": "1"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	minutes, daily := &rules.Rules[0], &rules.Rules[1]
+
+	q := minutes.Quality("Every 5 minutes", rules.Dictionaries)
+	if q.Coverage != 1 || len(q.Leftover) != 0 || q.Specificity < 0.9 || q.Score < 0.95 {
+		t.Errorf("full match quality = %+v", q)
+	}
+
+	q = minutes.Quality("please run every 5 minutes", rules.Dictionaries)
+	if q.Coverage != 0.6 || !reflect.DeepEqual(q.Leftover, []string{"please", "run"}) || q.Significant != 0 {
+		t.Errorf("partial match quality = %+v", q)
+	}
+
+	// Numbers and dictionary words left over weigh more than filler words
+	withFiller := minutes.Quality("every 5 minutes, okay then", rules.Dictionaries)
+	withDetails := minutes.Quality("every 5 minutes on monday 9", rules.Dictionaries)
+	if withDetails.Significant != 2 || withDetails.Score >= withFiller.Score {
+		t.Errorf("significant leftovers not penalized: %+v vs %+v", withDetails, withFiller)
+	}
+
+	// A wildcard pattern covers everything but spells little out
+	q = daily.Quality("daily except on the weekend", rules.Dictionaries)
+	if q.Coverage != 1 || q.Specificity > 0.25 || q.Score > 0.85 {
+		t.Errorf("wildcard match quality = %+v", q)
+	}
+
+	if q := minutes.Quality("hourly", rules.Dictionaries); !reflect.DeepEqual(q, MatchQuality{}) {
+		t.Errorf("quality without a match = %+v", q)
+	}
+}
@@ -1,29 +1,35 @@
 package rules
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"io/ioutil"
 	"path/filepath"
 	"regexp"
-	"strconv"
-	"strings"
+	"time"
 )
 
 //go:embed *.yaml
 var rules embed.FS
 
-// Rule represents a rule for converting human-readable expression to cron
+// Rule represents a rule for converting human-readable expression to cron.
+// A rule with Unsupported set recognizes a phrasing cron cannot express: it
+// needs no format, and the expressions it matches fail with ErrUnsupported.
 type Rule struct {
 	Name            string                      `yaml:"name"`
 	Pattern         string                      `yaml:"pattern"`
-	Variables       map[string]int              `yaml:"variables"`
-	Dictionaries    map[string]string           `yaml:"dictionaries"`
-	Format          string                      `yaml:"format"`
-	DefaultValues   map[string]string           `yaml:"default_values"`
-	SpecialCases    []SpecialCase               `yaml:"special_cases"`
-	Transformations map[string][]Transformation `yaml:"transformations"`
+	Variables       map[string]int              `yaml:"variables,omitempty"`
+	Dictionaries    map[string]string           `yaml:"dictionaries,omitempty"`
+	Format          string                      `yaml:"format,omitempty"`
+	Unsupported     string                      `yaml:"unsupported,omitempty"`
+	DefaultValues   map[string]string           `yaml:"default_values,omitempty"`
+	SpecialCases    []SpecialCase               `yaml:"special_cases,omitempty"`
+	Transformations map[string][]Transformation `yaml:"transformations,omitempty"`
+	Examples        []Example                   `yaml:"examples,omitempty"`
 
 	compiledPattern *regexp.Regexp
 }
@@ -34,6 +40,14 @@ type SpecialCase struct {
 	Format    string `yaml:"format"`
 }
 
+// Example is an input with the cron expression the rule set must produce for it.
+// When Error is set, the conversion of the input is expected to fail.
+type Example struct {
+	Input string `yaml:"input"`
+	Cron  string `yaml:"cron,omitempty"`
+	Error bool   `yaml:"error,omitempty"`
+}
+
 // Transformation represents a variable transformation
 type Transformation struct {
 	Condition string `yaml:"condition"`
@@ -44,7 +58,9 @@ type Transformation struct {
 type Rules struct {
 	Language     string                       `yaml:"language"`
 	Rules        []Rule                       `yaml:"rules"`
-	Dictionaries map[string]map[string]string `yaml:"dictionaries"`
+	Dictionaries map[string]map[string]string `yaml:"dictionaries,omitempty"`
+	// Timezones maps time zone phrases of the language to IANA zone names
+	Timezones map[string]string `yaml:"timezones,omitempty"`
 }
 
 // CompilePattern compiles the regular expression for the rule
@@ -67,86 +83,48 @@ func (r *Rule) Match(expression string) []string {
 // ApplyTransformations applies transformations to variables
 func (r *Rule) ApplyTransformations(variables map[string]string, dictionaries map[string]map[string]string) error {
 	for varName, transformations := range r.Transformations {
-		value, exists := variables[varName]
-		if !exists {
+		if _, exists := variables[varName]; !exists {
 			continue
 		}
 
 		for _, t := range transformations {
-			// Replace variables in the condition
-			condition := t.Condition
-			for k, v := range variables {
-				condition = strings.ReplaceAll(condition, k, fmt.Sprintf("\"%s\"", v))
+			ok, err := evalCondition(t.Condition, variables)
+			if err != nil {
+				return fmt.Errorf("rule %s: %w", r.Name, err)
+			}
+			if !ok {
+				continue
 			}
 
-			// Evaluate the condition
-			// Note: For simplicity, we use basic condition evaluation.
-			// In a real application, it's better to use a library for expressions
-			if EvalCondition(condition) {
-				// Replace variables in the operation
-				operation := t.Operation
-				for k, v := range variables {
-					operation = strings.ReplaceAll(operation, k, fmt.Sprintf("\"%s\"", v))
-				}
-
-				// Perform the operation
-				result, err := evalOperation(operation, value)
-				if err != nil {
-					return err
-				}
-
-				variables[varName] = result
-				break
+			result, err := evalOperation(t.Operation, variables)
+			if err != nil {
+				return fmt.Errorf("rule %s: %w", r.Name, err)
 			}
+
+			variables[varName] = result
+			break
 		}
 	}
 
 	return nil
 }
 
-// evalCondition evaluates a simple condition
-// Simplified version for example
+// EvalCondition evaluates a condition without variables, identifiers
+// evaluate to their own names
 func EvalCondition(condition string) bool {
-	if strings.Contains(condition, "==") {
-		parts := strings.Split(condition, "==")
-		left := strings.TrimSpace(parts[0])
-		right := strings.TrimSpace(parts[1])
-		return left == right
-	}
-
-	if strings.Contains(condition, "<") {
-		parts := strings.Split(condition, "<")
-		left, _ := strconv.Atoi(strings.TrimSpace(parts[0]))
-		right, _ := strconv.Atoi(strings.TrimSpace(parts[1]))
-		return left < right
-	}
-
-	if strings.Contains(condition, ">") {
-		parts := strings.Split(condition, ">")
-		left, _ := strconv.Atoi(strings.TrimSpace(parts[0]))
-		right, _ := strconv.Atoi(strings.TrimSpace(parts[1]))
-		return left > right
-	}
-
-	return false
+	ok, err := evalCondition(condition, nil)
+	return err == nil && ok
 }
 
-// evalOperation evaluates a simple operation
-// Simplified version for example
-func evalOperation(operation, currentValue string) (string, error) {
-	if strings.Contains(operation, "+") {
-		parts := strings.Split(operation, "+")
-		left, _ := strconv.Atoi(strings.TrimSpace(parts[0]))
-		right, _ := strconv.Atoi(strings.TrimSpace(parts[1]))
-		return strconv.Itoa(left + right), nil
-	}
-
-	// If the operation is just a string (e.g., 'first')
-	if strings.HasPrefix(operation, "'") && strings.HasSuffix(operation, "'") {
-		return operation[1 : len(operation)-1], nil
+// Fingerprint returns a digest of the rule set that changes whenever a rule,
+// dictionary or time zone phrase changes
+func (r *Rules) Fingerprint() string {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return ""
 	}
-
-	return operation, nil
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
 }
 
 // LoadAllRules loads rules for all languages from a directory
@@ -181,12 +159,67 @@ func LoadRulesFromFile(filePath string) (*Rules, error) {
 		return nil, fmt.Errorf("error parsing YAML: %w", err)
 	}
 
-	// Compile regular expressions for all rules
-	for i := range rules.Rules {
-		if err := rules.Rules[i].CompilePattern(); err != nil {
-			return nil, fmt.Errorf("error compiling regex for rule %s: %w", rules.Rules[i].Name, err)
-		}
+	if err := rules.Compile(); err != nil {
+		return nil, err
 	}
 
 	return &rules, nil
 }
+
+// Compile validates all rules and compiles their regular expressions.
+// Rules loaded from YAML and rules assembled in Go both go through it.
+func (r *Rules) Compile() error {
+	if r.Language == "" {
+		return fmt.Errorf("rules language is not set")
+	}
+
+	names := make(map[string]bool, len(r.Rules))
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule #%d has no name", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if err := rule.CompilePattern(); err != nil {
+			return fmt.Errorf("error compiling regex for rule %s: %w", rule.Name, err)
+		}
+		if err := rule.validate(r.Dictionaries); err != nil {
+			return fmt.Errorf("invalid rule %s: %w", rule.Name, err)
+		}
+	}
+
+	for phrase, zone := range r.Timezones {
+		if _, err := time.LoadLocation(zone); err != nil {
+			return fmt.Errorf("invalid time zone %s for %q: %w", zone, phrase, err)
+		}
+	}
+
+	return nil
+}
+
+// validate checks that the rule is consistent with its compiled pattern and
+// with the dictionaries of the rule set
+func (r *Rule) validate(dictionaries map[string]map[string]string) error {
+	if r.Format == "" && r.Unsupported == "" {
+		return fmt.Errorf("format is empty")
+	}
+
+	groups := r.compiledPattern.NumSubexp()
+	for name, index := range r.Variables {
+		if index < 1 || index > groups {
+			return fmt.Errorf("variable %s refers to group %d, pattern has %d groups", name, index, groups)
+		}
+	}
+
+	for name, dictName := range r.Dictionaries {
+		if _, ok := dictionaries[dictName]; !ok {
+			return fmt.Errorf("variable %s refers to unknown dictionary %s", name, dictName)
+		}
+	}
+
+	return nil
+}
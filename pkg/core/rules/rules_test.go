@@ -3,4 +3,29 @@ package rules
 import "testing"
 
 func TestRules(t *testing.T) {
+	allRules, err := LoadAllRules(".")
+	if err != nil {
+		t.Fatalf("LoadAllRules() error = %v", err)
+	}
+
+	for lang, rules := range allRules {
+		t.Run(lang, func(t *testing.T) {
+			results := RunExamples(rules)
+			if len(results) == 0 {
+				t.Fatal("no examples")
+			}
+
+			for _, result := range results {
+				if !result.Passed {
+					t.Errorf("%s: %q\n%s", result.Rule, result.Example.Input, result.Diff())
+				}
+			}
+
+			for _, overlap := range Analyze(rules).Overlaps {
+				if overlap.Shadowed {
+					t.Errorf("%s: %q is shadowed by %s", overlap.Rule, overlap.Input, overlap.Other)
+				}
+			}
+		})
+	}
 }
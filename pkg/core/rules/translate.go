@@ -0,0 +1,122 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMatch is returned when no rule matches an expression
+var ErrNoMatch = errors.New("unsupported expression format")
+
+// ErrUnsupported is returned for expressions matched by a rule marked
+// unsupported, whose schedule cron cannot express
+var ErrUnsupported = errors.New("schedule cannot be expressed in cron")
+
+// Convert converts an expression to cron format with the first matching rule.
+// It also returns the matched rule, which is nil when no rule matches.
+func (r *Rules) Convert(expression string) (string, *Rule, error) {
+	// Convert the expression to lowercase for standardization
+	expr := strings.ToLower(strings.TrimSpace(expression))
+
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if match := rule.Match(expr); match != nil {
+			cronExpr, err := rule.Translate(match, r.Dictionaries)
+			return cronExpr, rule, err
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", ErrNoMatch, expression)
+}
+
+// Translate converts a match of the rule pattern to a cron expression
+func (r *Rule) Translate(match []string, dictionaries map[string]map[string]string) (string, error) {
+	if r.Unsupported != "" {
+		return "", fmt.Errorf("%w: %s", ErrUnsupported, r.Unsupported)
+	}
+
+	// Extract variables from the match
+	variables := make(map[string]string)
+	for name, index := range r.Variables {
+		if index < len(match) {
+			variables[name] = match[index]
+		}
+	}
+
+	// Apply default values for missing variables
+	for name, value := range r.DefaultValues {
+		if _, exists := variables[name]; !exists || variables[name] == "" {
+			variables[name] = value
+		}
+	}
+
+	// Convert string variables to numeric if needed
+	for name, value := range variables {
+		if name == "hour" || name == "minute" || name == "day" {
+			if i, err := strconv.Atoi(value); err == nil {
+				variables[name] = strconv.Itoa(i)
+			}
+		}
+	}
+
+	// Apply transformations to variables
+	if err := r.ApplyTransformations(variables, dictionaries); err != nil {
+		return "", err
+	}
+
+	// Check special cases
+	for _, specialCase := range r.SpecialCases {
+		ok, err := evalCondition(specialCase.Condition, variables)
+		if err != nil {
+			return "", fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		if ok {
+			return r.applyFormat(specialCase.Format, variables, dictionaries)
+		}
+	}
+
+	// Use standard format
+	return r.applyFormat(r.Format, variables, dictionaries)
+}
+
+// applyFormat applies a format with variable and dictionary value substitution
+func (r *Rule) applyFormat(format string, variables map[string]string, dictionaries map[string]map[string]string) (string, error) {
+	// Replace longer names first, so %minutes is not clobbered by %minute
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	result := format
+	for _, name := range names {
+		value := variables[name]
+
+		// Check if we need to use a dictionary for this variable.
+		// Optional groups that did not match stay empty.
+		if dictName, ok := r.Dictionaries[name]; ok && value != "" {
+			dict, dictExists := dictionaries[dictName]
+			if !dictExists {
+				return "", fmt.Errorf("dictionary '%s' not found", dictName)
+			}
+
+			dictValue, valueExists := dict[value]
+			if !valueExists {
+				return "", fmt.Errorf("value '%s' not found in dictionary '%s'", value, dictName)
+			}
+			value = dictValue
+		}
+
+		result = strings.ReplaceAll(result, "%"+name, value)
+	}
+
+	return result, nil
+}
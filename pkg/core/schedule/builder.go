@@ -0,0 +1,180 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+// Builder builds a Schedule in code:
+//
+//	schedule.Every().Weekday(time.Monday, time.Friday).At(9, 30).In(loc)
+//
+// Schedules restricted to some days fire at midnight until At sets a time.
+// Invalid values are reported by Schedule, Render and Describe.
+type Builder struct {
+	s     Schedule
+	timed bool
+	dated bool
+	err   error
+}
+
+// Every starts a schedule firing every minute
+func Every() *Builder {
+	return &Builder{}
+}
+
+// Minute fires the schedule every minute
+func (b *Builder) Minute() *Builder {
+	b.s.Minutes, b.s.Hours = nil, nil
+	b.timed = true
+	return b
+}
+
+// Minutes fires the schedule every n minutes
+func (b *Builder) Minutes(n int) *Builder {
+	if n < 1 {
+		b.fail(fmt.Errorf("minute interval %d must be positive", n))
+	}
+	b.s.Minutes, b.s.Hours = Steps(n), nil
+	b.timed = true
+	return b
+}
+
+// Hour fires the schedule at the start of every hour
+func (b *Builder) Hour() *Builder {
+	b.s.Minutes, b.s.Hours = Values(0), nil
+	b.timed = true
+	return b
+}
+
+// Hours fires the schedule at the start of every n-th hour
+func (b *Builder) Hours(n int) *Builder {
+	if n < 1 {
+		b.fail(fmt.Errorf("hour interval %d must be positive", n))
+	}
+	b.s.Minutes, b.s.Hours = Values(0), Steps(n)
+	b.timed = true
+	return b
+}
+
+// Day fires the schedule every day
+func (b *Builder) Day() *Builder {
+	b.dated = true
+	return b
+}
+
+// Weekday fires the schedule on the given days of the week
+func (b *Builder) Weekday(days ...time.Weekday) *Builder {
+	for _, d := range days {
+		b.s.Weekdays = append(b.s.Weekdays, Term{Kind: Value, From: int(d)})
+	}
+	b.dated = true
+	return b
+}
+
+// Workday fires the schedule Monday to Friday
+func (b *Builder) Workday() *Builder {
+	b.s.Weekdays = append(b.s.Weekdays, Term{Kind: Range, From: int(time.Monday), To: int(time.Friday)})
+	b.dated = true
+	return b
+}
+
+// Weekend fires the schedule on Saturday and Sunday
+func (b *Builder) Weekend() *Builder {
+	return b.Weekday(time.Saturday, time.Sunday)
+}
+
+// Nth fires the schedule on the n-th given weekday of the month, 1 to 5, or
+// on the last one with -1
+func (b *Builder) Nth(n int, day time.Weekday) *Builder {
+	switch {
+	case n == -1:
+		b.s.Weekdays = append(b.s.Weekdays, Term{Kind: Last, From: int(day)})
+	case n >= 1 && n <= 5:
+		b.s.Weekdays = append(b.s.Weekdays, Term{Kind: Nth, From: int(day), N: n})
+	default:
+		b.fail(fmt.Errorf("occurrence %d out of range, expected 1-5 or -1", n))
+	}
+	b.dated = true
+	return b
+}
+
+// DayOfMonth fires the schedule on the given days of the month
+func (b *Builder) DayOfMonth(days ...int) *Builder {
+	b.s.Days = append(b.s.Days, Values(days...)...)
+	b.dated = true
+	return b
+}
+
+// LastDayOfMonth fires the schedule on the last day of the month
+func (b *Builder) LastDayOfMonth() *Builder {
+	b.s.Days = append(b.s.Days, Term{Kind: Last})
+	b.dated = true
+	return b
+}
+
+// Month restricts the schedule to the given months
+func (b *Builder) Month(months ...time.Month) *Builder {
+	for _, m := range months {
+		b.s.Months = append(b.s.Months, Term{Kind: Value, From: int(m)})
+	}
+	b.dated = true
+	return b
+}
+
+// At fires the schedule at the given time of day
+func (b *Builder) At(hour, minute int) *Builder {
+	b.s.SetTime(hour, minute)
+	b.timed = true
+	b.dated = true
+	return b
+}
+
+// In sets the time zone of the schedule
+func (b *Builder) In(loc *time.Location) *Builder {
+	b.s.Location = loc
+	return b
+}
+
+// Schedule returns the built schedule
+func (b *Builder) Schedule() (*Schedule, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	s := b.s.Clone()
+	if b.dated && !b.timed {
+		s.SetTime(0, 0)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Render writes the schedule in a dialect
+func (b *Builder) Render(dialect cron.Dialect) (string, error) {
+	s, err := b.Schedule()
+	if err != nil {
+		return "", err
+	}
+	return s.Render(dialect)
+}
+
+// Describe writes the schedule as text in a language, see Schedule.Describe
+func (b *Builder) Describe(language string) (string, error) {
+	s, err := b.Schedule()
+	if err != nil {
+		return "", err
+	}
+	return s.Describe(language)
+}
+
+// fail keeps the first error of the chain
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,139 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/flaticols/cronscribe/pkg/core/schedule"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		builder *schedule.Builder
+		want    string
+	}{
+		{schedule.Every(), "* * * * *"},
+		{schedule.Every().Minutes(15), "*/15 * * * *"},
+		{schedule.Every().Hour(), "0 * * * *"},
+		{schedule.Every().Hours(2), "0 */2 * * *"},
+		{schedule.Every().Day(), "0 0 * * *"},
+		{schedule.Every().Day().At(9, 30), "30 9 * * *"},
+		{schedule.Every().Weekday(time.Monday, time.Friday).At(9, 30), "30 9 * * 1,5"},
+		{schedule.Every().Workday().At(8, 0), "0 8 * * 1-5"},
+		{schedule.Every().Weekend(), "0 0 * * 6,0"},
+		{schedule.Every().Minutes(10).Workday(), "*/10 * * * 1-5"},
+		{schedule.Every().Nth(2, time.Tuesday).At(10, 0), "0 10 * * 2#2"},
+		{schedule.Every().Nth(-1, time.Friday).At(17, 0), "0 17 * * 5L"},
+		{schedule.Every().DayOfMonth(1, 15), "0 0 1,15 * *"},
+		{schedule.Every().LastDayOfMonth().At(23, 0), "0 23 L * *"},
+		{schedule.Every().Month(time.January, time.July).DayOfMonth(1), "0 0 1 1,7 *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			s, err := tt.builder.Schedule()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.String(); got != tt.want {
+				t.Errorf("Schedule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	for name, builder := range map[string]*schedule.Builder{
+		"hour 24":          schedule.Every().Day().At(24, 0),
+		"day 32":           schedule.Every().DayOfMonth(32),
+		"interval 0":       schedule.Every().Minutes(0),
+		"sixth occurrence": schedule.Every().Nth(6, time.Monday),
+	} {
+		if _, err := builder.Render(cron.Standard); err == nil {
+			t.Errorf("%s: Render() succeeded", name)
+		}
+	}
+}
+
+func TestBuilderMatchesRules(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := core.New("../rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := cs.ConvertResult("every monday at 9am Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := schedule.Every().Weekday(time.Monday).At(9, 0).In(amsterdam).Schedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := result.Schedule()
+	if built.String() != parsed.String() || built.Location.String() != parsed.Location.String() {
+		t.Errorf("builder made %q in %s, rules %q in %s", built, built.Location, parsed, parsed.Location)
+	}
+
+	rendered, err := schedule.Every().Weekday(time.Monday).At(9, 0).In(amsterdam).Render(cron.Standard.WithTimezonePrefix("TZ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered != "TZ=Europe/Amsterdam 0 9 * * 1" {
+		t.Errorf("Render() = %q", rendered)
+	}
+}
+
+// TestDescribeRoundTrip checks that the rules convert descriptions back to the same schedule
+func TestDescribeRoundTrip(t *testing.T) {
+	builders := []*schedule.Builder{
+		schedule.Every().Day().At(9, 0),
+		schedule.Every().Day().At(21, 30),
+		schedule.Every().Weekday(time.Monday).At(9, 30),
+		schedule.Every().Weekday(time.Wednesday).At(15, 0),
+		schedule.Every().DayOfMonth(15).At(14, 30),
+		schedule.Every().Month(time.December).DayOfMonth(25).At(21, 0),
+		schedule.Every().LastDayOfMonth().At(18, 0),
+		schedule.Every().Hour(),
+		schedule.Every().Minutes(15),
+		schedule.Every().Hours(2),
+		schedule.Every().Nth(1, time.Monday),
+		schedule.Every().Nth(-1, time.Friday),
+	}
+
+	cs, err := core.New("../rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, language := range schedule.Languages() {
+		if err := cs.SetLanguage(language); err != nil {
+			t.Fatal(err)
+		}
+		for _, builder := range builders {
+			s, err := builder.Schedule()
+			if err != nil {
+				t.Fatal(err)
+			}
+			text, err := s.Describe(language)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := cs.Convert(text)
+			if err != nil {
+				t.Errorf("%s: Convert(%q) failed: %v", language, text, err)
+				continue
+			}
+			if got != s.String() {
+				t.Errorf("%s: %q converts to %q, want %q", language, text, got, s)
+			}
+		}
+	}
+}
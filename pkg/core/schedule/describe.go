@@ -0,0 +1,475 @@
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// phrases is the wording of schedules in a language. Common schedules are
+// worded like the examples of the language's YAML rules, so core.CronScribe
+// converts the description back to the same expression.
+type phrases struct {
+	everyMinute  string
+	everyMinutes func(n int) string
+	everyHour    string
+	everyHours   func(n int) string
+	atMinute     func(m int) string
+	at           string
+	clock        func(h, m int) string
+	everyDay     string
+	weekdays     func(days []int) string
+	weekdayRange func(from, to int) string
+	nth          func(n, day int) string
+	monthDays    func(days []int) string
+	monthDay     func(month, day int) string
+	lastDay      string
+	lastOffset   func(n int) string
+	lastWeekday  string
+	nearest      func(day int) string
+	months       func(months []int) string
+	and          string
+	or           string
+	labels       [7]string
+}
+
+// Describe writes the schedule as text in a language: en, nl or ru
+func (s *Schedule) Describe(language string) (string, error) {
+	p, ok := descriptions[language]
+	if !ok {
+		return "", fmt.Errorf("no descriptions for language %q", language)
+	}
+
+	timeText, clock := p.time(s)
+	days := p.days(s)
+	if days == "" && clock {
+		days = p.everyDay
+	}
+	if months := p.monthsText(s); months != "" {
+		days = strings.TrimSpace(days + " " + months)
+	}
+
+	var text string
+	switch {
+	case days == "":
+		text = timeText
+	case clock:
+		text = days + " " + timeText
+	default:
+		text = timeText + ", " + days
+	}
+
+	if !s.Seconds.isZero() {
+		text = p.generic(secondField, s.Seconds) + ", " + text
+	}
+	if !s.Years.IsAny() {
+		text += ", " + p.generic(yearField, s.Years)
+	}
+	if s.Location != nil && s.Location != time.Local {
+		text += " " + s.Location.String()
+	}
+	return text, nil
+}
+
+// Languages returns the languages schedules can be described in
+func Languages() []string {
+	languages := make([]string, 0, len(descriptions))
+	for language := range descriptions {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// time describes the minutes and hours, and reports whether they are clock times
+func (p *phrases) time(s *Schedule) (string, bool) {
+	minutes, plainMinutes := s.Minutes.Ints()
+	hours, plainHours := s.Hours.Ints()
+	minuteStep, minuteEvery := s.Minutes.step()
+	hourStep, hourEvery := s.Hours.step()
+
+	switch {
+	case s.Minutes.IsAny() && s.Hours.IsAny():
+		return p.everyMinute, false
+	case minuteEvery && s.Hours.IsAny():
+		return p.everyMinutes(minuteStep), false
+	case plainMinutes && len(minutes) == 1 && s.Hours.IsAny():
+		return p.hourly(p.everyHour, minutes[0]), false
+	case plainMinutes && len(minutes) == 1 && hourEvery:
+		return p.hourly(p.everyHours(hourStep), minutes[0]), false
+	case plainMinutes && plainHours && len(minutes)*len(hours) <= 6:
+		hours, minutes = slices.Sorted(slices.Values(hours)), slices.Sorted(slices.Values(minutes))
+		var times []string
+		for _, h := range hours {
+			for _, m := range minutes {
+				times = append(times, p.clock(h, m))
+			}
+		}
+		return p.at + " " + join(times, p.and), true
+	}
+
+	var parts []string
+	switch {
+	case minuteEvery:
+		parts = append(parts, p.everyMinutes(minuteStep))
+	case !s.Minutes.IsAny():
+		parts = append(parts, p.generic(minuteField, s.Minutes))
+	}
+	if !s.Hours.IsAny() {
+		parts = append(parts, p.generic(hourField, s.Hours))
+	}
+	return strings.Join(parts, ", "), false
+}
+
+// hourly adds the minute to an hourly phrase
+func (p *phrases) hourly(phrase string, minute int) string {
+	if minute == 0 {
+		return phrase
+	}
+	return phrase + " " + p.atMinute(minute)
+}
+
+// days describes the days of the month and of the week
+func (p *phrases) days(s *Schedule) string {
+	var pieces, dayPieces []string
+
+	var days []int
+	for _, t := range s.Days {
+		switch {
+		case t.Kind == Value && t.Step == 0:
+			days = append(days, t.From)
+		case t.Kind == Last && t.Offset == 0:
+			dayPieces = append(dayPieces, p.lastDay)
+		case t.Kind == Last:
+			dayPieces = append(dayPieces, p.lastOffset(t.Offset))
+		case t.Kind == LastWeekday:
+			dayPieces = append(dayPieces, p.lastWeekday)
+		case t.Kind == NearestWeekday:
+			dayPieces = append(dayPieces, p.nearest(t.From))
+		default:
+			dayPieces = append(dayPieces, p.generic(dayField, Field{t}))
+		}
+	}
+	if len(days) > 0 {
+		if month, ok := p.singleMonth(s); ok {
+			pieces = append(pieces, p.monthDay(month, days[0]))
+		} else {
+			pieces = append(pieces, p.monthDays(slices.Sorted(slices.Values(days))))
+		}
+	}
+	pieces = append(pieces, dayPieces...)
+
+	var weekdays []int
+	var weekdayPieces []string
+	for _, t := range s.Weekdays {
+		switch {
+		case t.Kind == Value && t.Step == 0:
+			if !slices.Contains(weekdays, t.From%7) {
+				weekdays = append(weekdays, t.From%7)
+			}
+		case t.Kind == Range && t.Step == 0:
+			weekdayPieces = append(weekdayPieces, p.weekdayRange(t.From%7, t.To%7))
+		case t.Kind == Nth:
+			weekdayPieces = append(weekdayPieces, p.nth(t.N, t.From%7))
+		case t.Kind == Last:
+			weekdayPieces = append(weekdayPieces, p.nth(-1, t.From%7))
+		default:
+			weekdayPieces = append(weekdayPieces, p.generic(weekdayField, Field{t}))
+		}
+	}
+	if len(weekdays) > 0 {
+		// Monday first, Sunday last
+		slices.SortFunc(weekdays, func(a, b int) int { return (a+6)%7 - (b+6)%7 })
+		pieces = append(pieces, p.weekdays(weekdays))
+	}
+	pieces = append(pieces, weekdayPieces...)
+
+	return join(pieces, p.or)
+}
+
+// singleMonth reports the month of schedules firing on one day of one month,
+// which read as a date
+func (p *phrases) singleMonth(s *Schedule) (int, bool) {
+	months, ok := s.Months.Ints()
+	days, plainDays := s.Days.Ints()
+	if !ok || !plainDays || len(months) != 1 || len(days) != 1 {
+		return 0, false
+	}
+	return months[0], true
+}
+
+// monthsText describes the months, unless they are part of a date
+func (p *phrases) monthsText(s *Schedule) string {
+	if s.Months.IsAny() {
+		return ""
+	}
+	if _, ok := p.singleMonth(s); ok {
+		return ""
+	}
+	if months, ok := s.Months.Ints(); ok {
+		return p.months(slices.Sorted(slices.Values(months)))
+	}
+	return p.generic(monthField, s.Months)
+}
+
+// generic describes a field with its cron syntax
+func (p *phrases) generic(l layout, f Field) string {
+	return p.labels[l] + " " + f.render(l)
+}
+
+// isZero reports whether a seconds field fires at second 0 only
+func (f Field) isZero() bool {
+	values, ok := f.Ints()
+	return f == nil || ok && len(values) == 1 && values[0] == 0
+}
+
+// step returns the step of fields made of a single */n term
+func (f Field) step() (int, bool) {
+	if len(f) == 1 && f[0].Kind == Any && f[0].Step > 1 {
+		return f[0].Step, true
+	}
+	return 0, false
+}
+
+// join lists items as "a, b and c"
+func join(items []string, and string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " " + and + " " + items[len(items)-1]
+}
+
+// mapInts formats numbers with a function
+func mapInts(values []int, format func(int) string) []string {
+	texts := make([]string, len(values))
+	for i, v := range values {
+		texts[i] = format(v)
+	}
+	return texts
+}
+
+// name returns a function picking names by number
+func name(names []string) func(int) string {
+	return func(n int) string {
+		return names[n]
+	}
+}
+
+// englishOrdinal writes 1st, 2nd, 3rd and so on
+func englishOrdinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// englishClock writes 9am, 3:45pm and 12am
+func englishClock(h, m int) string {
+	suffix := "am"
+	if h >= 12 {
+		suffix = "pm"
+	}
+	h %= 12
+	if h == 0 {
+		h = 12
+	}
+	if m == 0 {
+		return strconv.Itoa(h) + suffix
+	}
+	return fmt.Sprintf("%d:%02d%s", h, m, suffix)
+}
+
+// clock24 writes 9:00 and 17:30
+func clock24(h, m int) string {
+	return fmt.Sprintf("%d:%02d", h, m)
+}
+
+// englishPlural adds an s unless n is 1
+func englishPlural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return strconv.Itoa(n) + " " + word + "s"
+}
+
+// russianPlural picks the form of a noun after a number
+func russianPlural(n int, one, few, many string) string {
+	form := many
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		form = one
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		form = few
+	}
+	return strconv.Itoa(n) + " " + form
+}
+
+var (
+	englishWeekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	englishMonths   = []string{"", "january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"}
+	englishNth      = []string{"last", "first", "second", "third", "fourth", "fifth"}
+
+	dutchWeekdays = []string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"}
+	dutchMonths   = []string{"", "januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"}
+	dutchNth      = []string{"laatste", "eerste", "tweede", "derde", "vierde", "vijfde"}
+
+	// Russian weekdays in the nominative, accusative, genitive and plural dative,
+	// with their grammatical gender
+	russianWeekdays   = []string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+	russianAccusative = []string{"воскресенье", "понедельник", "вторник", "среду", "четверг", "пятницу", "субботу"}
+	russianGenitive   = []string{"воскресенья", "понедельника", "вторника", "среды", "четверга", "пятницы", "субботы"}
+	russianDative     = []string{"воскресеньям", "понедельникам", "вторникам", "средам", "четвергам", "пятницам", "субботам"}
+	russianGender     = []int{2, 0, 0, 1, 0, 1, 1}
+	// Every and the ordinals by gender: masculine, feminine, neuter
+	russianEvery    = [3]string{"каждый", "каждую", "каждое"}
+	russianEveryNth = [3]string{"каждый", "каждая", "каждое"}
+	russianNth      = [3][]string{
+		{"последний", "первый", "второй", "третий", "четвертый", "пятый"},
+		{"последняя", "первая", "вторая", "третья", "четвертая", "пятая"},
+		{"последнее", "первое", "второе", "третье", "четвертое", "пятое"},
+	}
+	russianMonthsGenitive      = []string{"", "января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"}
+	russianMonthsPrepositional = []string{"", "январе", "феврале", "марте", "апреле", "мае", "июне", "июле", "августе", "сентябре", "октябре", "ноябре", "декабре"}
+)
+
+// ordinalIndex maps an occurrence to its index in the ordinal tables, the last being 0
+func ordinalIndex(n int) int {
+	return max(n, 0)
+}
+
+var descriptions = map[string]*phrases{
+	"en": {
+		everyMinute:  "every minute",
+		everyMinutes: func(n int) string { return "every " + englishPlural(n, "minute") },
+		everyHour:    "every hour",
+		everyHours:   func(n int) string { return "every " + englishPlural(n, "hour") },
+		atMinute:     func(m int) string { return fmt.Sprintf("at minute %d", m) },
+		at:           "at",
+		clock:        englishClock,
+		everyDay:     "every day",
+		weekdays: func(days []int) string {
+			return "every " + join(mapInts(days, name(englishWeekdays)), "and")
+		},
+		weekdayRange: func(from, to int) string {
+			return "every " + englishWeekdays[from] + " through " + englishWeekdays[to]
+		},
+		nth: func(n, day int) string {
+			return "every " + englishNth[ordinalIndex(n)] + " " + englishWeekdays[day] + " of the month"
+		},
+		monthDays: func(days []int) string {
+			return "every " + join(mapInts(days, englishOrdinal), "and") + " of the month"
+		},
+		monthDay: func(month, day int) string {
+			return "every " + englishMonths[month] + " " + englishOrdinal(day)
+		},
+		lastDay:     "every last day of the month",
+		lastOffset:  func(n int) string { return englishPlural(n, "day") + " before the last day of the month" },
+		lastWeekday: "every last weekday of the month",
+		nearest:     func(day int) string { return "every weekday nearest the " + englishOrdinal(day) },
+		months: func(months []int) string {
+			return "in " + join(mapInts(months, name(englishMonths)), "and")
+		},
+		and:    "and",
+		or:     "or",
+		labels: [7]string{"seconds", "minutes", "hours", "days", "months", "weekdays", "years"},
+	},
+	"nl": {
+		everyMinute:  "elke minuut",
+		everyMinutes: func(n int) string { return fmt.Sprintf("elke %d minuten", n) },
+		everyHour:    "elk uur",
+		everyHours:   func(n int) string { return fmt.Sprintf("elke %d uur", n) },
+		atMinute:     func(m int) string { return fmt.Sprintf("op minuut %d", m) },
+		at:           "om",
+		clock:        clock24,
+		everyDay:     "elke dag",
+		weekdays: func(days []int) string {
+			return "elke " + join(mapInts(days, name(dutchWeekdays)), "en")
+		},
+		weekdayRange: func(from, to int) string {
+			return "elke " + dutchWeekdays[from] + " tot en met " + dutchWeekdays[to]
+		},
+		nth: func(n, day int) string {
+			return "elke " + dutchNth[ordinalIndex(n)] + " " + dutchWeekdays[day] + " van de maand"
+		},
+		monthDays: func(days []int) string {
+			return "elke " + join(mapInts(days, func(d int) string { return strconv.Itoa(d) + "e" }), "en") + " van de maand"
+		},
+		monthDay: func(month, day int) string {
+			return fmt.Sprintf("elke %s %d", dutchMonths[month], day)
+		},
+		lastDay: "elke laatste dag van de maand",
+		lastOffset: func(n int) string {
+			if n == 1 {
+				return "1 dag voor de laatste dag van de maand"
+			}
+			return fmt.Sprintf("%d dagen voor de laatste dag van de maand", n)
+		},
+		lastWeekday: "elke laatste werkdag van de maand",
+		nearest:     func(day int) string { return fmt.Sprintf("elke werkdag het dichtst bij de %de", day) },
+		months: func(months []int) string {
+			return "in " + join(mapInts(months, name(dutchMonths)), "en")
+		},
+		and:    "en",
+		or:     "of",
+		labels: [7]string{"seconden", "minuten", "uren", "dagen", "maanden", "weekdagen", "jaren"},
+	},
+	"ru": {
+		everyMinute: "каждую минуту",
+		everyMinutes: func(n int) string {
+			return "каждые " + russianPlural(n, "минуту", "минуты", "минут")
+		},
+		everyHour:  "каждый час",
+		everyHours: func(n int) string { return "каждые " + russianPlural(n, "час", "часа", "часов") },
+		atMinute:   func(m int) string { return "в " + russianPlural(m, "минуту", "минуты", "минут") },
+		at:         "в",
+		clock:      clock24,
+		everyDay:   "каждый день",
+		weekdays: func(days []int) string {
+			if len(days) == 1 {
+				return russianEvery[russianGender[days[0]]] + " " + russianAccusative[days[0]]
+			}
+			return "по " + join(mapInts(days, name(russianDative)), "и")
+		},
+		weekdayRange: func(from, to int) string {
+			return "с " + russianGenitive[from] + " по " + russianAccusative[to]
+		},
+		nth: func(n, day int) string {
+			gender := russianGender[day]
+			return russianEveryNth[gender] + " " + russianNth[gender][ordinalIndex(n)] + " " + russianWeekdays[day] + " месяца"
+		},
+		monthDays: func(days []int) string {
+			return "каждое " + join(mapInts(days, strconv.Itoa), "и") + " число месяца"
+		},
+		monthDay: func(month, day int) string {
+			return fmt.Sprintf("каждое %d %s", day, russianMonthsGenitive[month])
+		},
+		lastDay: "каждый последний день месяца",
+		lastOffset: func(n int) string {
+			return "за " + russianPlural(n, "день", "дня", "дней") + " до последнего дня месяца"
+		},
+		lastWeekday: "каждый последний рабочий день месяца",
+		nearest: func(day int) string {
+			return fmt.Sprintf("каждый рабочий день, ближайший к %d числу", day)
+		},
+		months: func(months []int) string {
+			return "в " + join(mapInts(months, name(russianMonthsPrepositional)), "и")
+		},
+		and:    "и",
+		or:     "или",
+		labels: [7]string{"секунды", "минуты", "часы", "дни", "месяцы", "дни недели", "годы"},
+	},
+}
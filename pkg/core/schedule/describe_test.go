@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		expr string
+		want map[string]string
+	}{
+		{"30 9 * * 1,5", map[string]string{
+			"en": "every monday and friday at 9:30am",
+			"nl": "elke maandag en vrijdag om 9:30",
+			"ru": "по понедельникам и пятницам в 9:30",
+		}},
+		{"0 9 * * 1-5", map[string]string{
+			"en": "every monday through friday at 9am",
+			"nl": "elke maandag tot en met vrijdag om 9:00",
+			"ru": "с понедельника по пятницу в 9:00",
+		}},
+		{"0 9,17 * * *", map[string]string{
+			"en": "every day at 9am and 5pm",
+			"nl": "elke dag om 9:00 en 17:00",
+			"ru": "каждый день в 9:00 и 17:00",
+		}},
+		{"*/10 * * * 0", map[string]string{
+			"en": "every 10 minutes, every sunday",
+			"nl": "elke 10 minuten, elke zondag",
+			"ru": "каждые 10 минут, каждое воскресенье",
+		}},
+		{"30 */3 * * *", map[string]string{
+			"en": "every 3 hours at minute 30",
+			"nl": "elke 3 uur op minuut 30",
+			"ru": "каждые 3 часа в 30 минут",
+		}},
+		{"0 0 1,15 1,7 *", map[string]string{
+			"en": "every 1st and 15th of the month in january and july at 12am",
+			"nl": "elke 1e en 15e van de maand in januari en juli om 0:00",
+			"ru": "каждое 1 и 15 число месяца в январе и июле в 0:00",
+		}},
+		{"0 8 LW * *", map[string]string{
+			"en": "every last weekday of the month at 8am",
+			"nl": "elke laatste werkdag van de maand om 8:00",
+			"ru": "каждый последний рабочий день месяца в 8:00",
+		}},
+		{"0 8 L-2 * 6#3", map[string]string{
+			"en": "2 days before the last day of the month or every third saturday of the month at 8am",
+			"nl": "2 dagen voor de laatste dag van de maand of elke derde zaterdag van de maand om 8:00",
+			"ru": "за 2 дня до последнего дня месяца или каждая третья суббота месяца в 8:00",
+		}},
+		{"0-30/5 9-17 * * *", map[string]string{
+			"en": "minutes 0-30/5, hours 9-17",
+			"nl": "minuten 0-30/5, uren 9-17",
+			"ru": "минуты 0-30/5, часы 9-17",
+		}},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for language, want := range tt.want {
+			got, err := s.Describe(language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("Describe(%q) of %s = %q, want %q", language, tt.expr, got, want)
+			}
+		}
+	}
+}
+
+func TestDescribeExtras(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Schedule{Seconds: Values(30), Minutes: Values(0), Hours: Values(6), Years: Values(2027), Location: amsterdam}
+	got, err := s.Describe("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "seconds 30, every day at 6am, years 2027 Europe/Amsterdam"; got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+
+	if _, err := s.Describe("xx"); err == nil {
+		t.Error("Describe() accepted an unknown language")
+	}
+}
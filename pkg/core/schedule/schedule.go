@@ -0,0 +1,385 @@
+// Package schedule models cron schedules as typed fields, so they can be
+// inspected and changed in code and rendered in any dialect
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+// Kind identifies the form of a Term
+type Kind int
+
+const (
+	// Any matches every value of the field, *, or every Step values, */15
+	Any Kind = iota
+	// Value matches From, or every Step values from From, 5/10
+	Value
+	// Range matches From through To, or every Step values between them, 1-5/2
+	Range
+	// Last is the last day of the month, or Offset days before it, L and L-3.
+	// In the day of week field it is the last From weekday of the month, 5L.
+	Last
+	// LastWeekday is the last Monday to Friday of the month, LW
+	LastWeekday
+	// NearestWeekday is the Monday to Friday closest to day From, 15W
+	NearestWeekday
+	// Nth is the N-th From weekday of the month, 2#3
+	Nth
+)
+
+// Term is one element of a field's comma-separated list
+type Term struct {
+	Kind Kind
+	// From is the value, the start of a range or the weekday or day of the modifiers
+	From int
+	// To is the end of a range
+	To int
+	// Step repeats the term every Step values, 0 when unset
+	Step int
+	// Offset is how many days before the end of the month a Last day falls
+	Offset int
+	// N is the occurrence of an Nth weekday, 1 to 5
+	N int
+}
+
+// Field is the list of terms of a cron field. An empty field matches every value.
+type Field []Term
+
+// Schedule is a cron schedule with typed fields. Weekdays are numbered from
+// Sunday, 0, to Saturday, 6.
+type Schedule struct {
+	// Seconds is nil for schedules firing at second 0, the 5-field default
+	Seconds  Field
+	Minutes  Field
+	Hours    Field
+	Days     Field
+	Months   Field
+	Weekdays Field
+	// Years is nil for schedules firing every year
+	Years Field
+	// Location is the time zone the schedule runs in, nil when unspecified
+	Location *time.Location
+}
+
+// Values returns a field matching the given values
+func Values(values ...int) Field {
+	field := make(Field, len(values))
+	for i, v := range values {
+		field[i] = Term{Kind: Value, From: v}
+	}
+	return field
+}
+
+// Between returns a field matching from through to
+func Between(from, to int) Field {
+	return Field{{Kind: Range, From: from, To: to}}
+}
+
+// Steps returns a field matching every step values, */step
+func Steps(step int) Field {
+	return Field{{Kind: Any, Step: step}}
+}
+
+// IsAny reports whether the field matches every value
+func (f Field) IsAny() bool {
+	for _, t := range f {
+		if t.Kind == Any && t.Step <= 1 {
+			return true
+		}
+	}
+	return len(f) == 0
+}
+
+// Ints returns the values of a field made of single values only
+func (f Field) Ints() ([]int, bool) {
+	if len(f) == 0 {
+		return nil, false
+	}
+	values := make([]int, len(f))
+	for i, t := range f {
+		if t.Kind != Value || t.Step > 0 {
+			return nil, false
+		}
+		values[i] = t.From
+	}
+	return values, true
+}
+
+// Parse parses a cron expression into a schedule
+func Parse(expr string) (*Schedule, error) {
+	e, err := cron.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return FromExpression(e)
+}
+
+// FromExpression converts a parsed expression into a schedule
+func FromExpression(e *cron.Expression) (*Schedule, error) {
+	s := &Schedule{Location: e.Location}
+	targets := [5]*Field{&s.Minutes, &s.Hours, &s.Days, &s.Months, &s.Weekdays}
+
+	for i, text := range e.Fields {
+		field, err := parseField(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field %q: %w", cron.Field(i), text, err)
+		}
+		*targets[i] = field
+	}
+
+	var err error
+	if e.Second != "" {
+		if s.Seconds, err = parseField(e.Second); err != nil {
+			return nil, fmt.Errorf("invalid %s field %q: %w", cron.Second, e.Second, err)
+		}
+	}
+	if e.Year != "" {
+		if s.Years, err = parseField(e.Year); err != nil {
+			return nil, fmt.Errorf("invalid %s field %q: %w", cron.Year, e.Year, err)
+		}
+	}
+	return s, nil
+}
+
+// parseField reads a normalized cron field
+func parseField(text string) (Field, error) {
+	if text == "*" {
+		return nil, nil
+	}
+
+	var field Field
+	for _, part := range strings.Split(text, ",") {
+		term, err := parseTerm(part)
+		if err != nil {
+			return nil, err
+		}
+		field = append(field, term)
+	}
+	return field, nil
+}
+
+// parseTerm reads a normalized term
+func parseTerm(text string) (Term, error) {
+	switch {
+	case text == "L":
+		return Term{Kind: Last}, nil
+	case text == "LW":
+		return Term{Kind: LastWeekday}, nil
+	case strings.HasPrefix(text, "L-"):
+		offset, err := strconv.Atoi(text[2:])
+		return Term{Kind: Last, Offset: offset}, err
+	case strings.HasSuffix(text, "W"):
+		day, err := strconv.Atoi(text[:len(text)-1])
+		return Term{Kind: NearestWeekday, From: day}, err
+	case strings.HasSuffix(text, "L"):
+		weekday, err := strconv.Atoi(text[:len(text)-1])
+		return Term{Kind: Last, From: weekday}, err
+	}
+
+	if weekday, n, ok := strings.Cut(text, "#"); ok {
+		from, err := strconv.Atoi(weekday)
+		if err != nil {
+			return Term{}, err
+		}
+		occurrence, err := strconv.Atoi(n)
+		return Term{Kind: Nth, From: from, N: occurrence}, err
+	}
+
+	var term Term
+	base, step, hasStep := strings.Cut(text, "/")
+	if hasStep {
+		n, err := strconv.Atoi(step)
+		if err != nil {
+			return Term{}, err
+		}
+		term.Step = n
+	}
+
+	if base == "*" {
+		term.Kind = Any
+		return term, nil
+	}
+
+	from, to, isRange := strings.Cut(base, "-")
+	var err error
+	if term.From, err = strconv.Atoi(from); err != nil {
+		return Term{}, err
+	}
+	term.Kind = Value
+	if isRange {
+		term.Kind = Range
+		if term.To, err = strconv.Atoi(to); err != nil {
+			return Term{}, err
+		}
+	}
+	return term, nil
+}
+
+// Expression converts the schedule into a cron expression. Schedules firing
+// on other seconds than 0 or in some years only need a dialect with seconds
+// or years to be rendered.
+func (s *Schedule) Expression() (*cron.Expression, error) {
+	fields := s.fields()
+	e, err := cron.Parse(strings.Join(fields[:], " "))
+	if err != nil {
+		return nil, err
+	}
+	e.Location = s.Location
+	return e, nil
+}
+
+// Validate checks that every term fits its field
+func (s *Schedule) Validate() error {
+	_, err := s.Expression()
+	return err
+}
+
+// Render writes the schedule in a dialect
+func (s *Schedule) Render(dialect cron.Dialect) (string, error) {
+	e, err := s.Expression()
+	if err != nil {
+		return "", err
+	}
+	return dialect.Render(e)
+}
+
+// String returns the fields of the schedule, with seconds first and years
+// last when they are set, without the time zone. Schedules with years always
+// write seconds, so the year is read as the seventh field.
+func (s *Schedule) String() string {
+	fields := s.fields()
+	parts := fields[1:6]
+	if s.Seconds != nil || s.Years != nil {
+		parts = fields[:6]
+	}
+	if s.Years != nil {
+		parts = append(parts, fields[6])
+	}
+	return strings.Join(parts, " ")
+}
+
+// Clone returns a copy of the schedule that can be changed independently
+func (s *Schedule) Clone() *Schedule {
+	c := *s
+	for _, f := range []*Field{&c.Seconds, &c.Minutes, &c.Hours, &c.Days, &c.Months, &c.Weekdays, &c.Years} {
+		if *f != nil {
+			*f = append(Field(nil), *f...)
+		}
+	}
+	return &c
+}
+
+// SetTime makes the schedule fire once a day at the given time, on the same days
+func (s *Schedule) SetTime(hour, minute int) {
+	s.Seconds = nil
+	s.Hours = Values(hour)
+	s.Minutes = Values(minute)
+}
+
+// Field returns a field of the 5-field layout
+func (s *Schedule) Field(f cron.Field) Field {
+	return *s.field(f)
+}
+
+// SetField replaces a field of the 5-field layout
+func (s *Schedule) SetField(f cron.Field, field Field) {
+	*s.field(f) = field
+}
+
+// field returns a pointer to a field of the 5-field layout
+func (s *Schedule) field(f cron.Field) *Field {
+	switch f {
+	case cron.Minute:
+		return &s.Minutes
+	case cron.Hour:
+		return &s.Hours
+	case cron.DayOfMonth:
+		return &s.Days
+	case cron.Month:
+		return &s.Months
+	case cron.DayOfWeek:
+		return &s.Weekdays
+	}
+	panic(fmt.Sprintf("schedule: unknown field %d", f))
+}
+
+// layout identifies the position of a field when rendering
+type layout int
+
+const (
+	secondField layout = iota
+	minuteField
+	hourField
+	dayField
+	monthField
+	weekdayField
+	yearField
+)
+
+// fields renders all seven fields
+func (s *Schedule) fields() [7]string {
+	return [7]string{
+		s.Seconds.render(secondField),
+		s.Minutes.render(minuteField),
+		s.Hours.render(hourField),
+		s.Days.render(dayField),
+		s.Months.render(monthField),
+		s.Weekdays.render(weekdayField),
+		s.Years.render(yearField),
+	}
+}
+
+// render writes a field in cron syntax
+func (f Field) render(l layout) string {
+	if len(f) == 0 {
+		if l == secondField {
+			return "0"
+		}
+		return "*"
+	}
+
+	parts := make([]string, len(f))
+	for i, t := range f {
+		parts[i] = t.render(l)
+	}
+	return strings.Join(parts, ",")
+}
+
+// render writes a term in cron syntax
+func (t Term) render(l layout) string {
+	var text string
+	switch t.Kind {
+	case Any:
+		text = "*"
+	case Value:
+		text = strconv.Itoa(t.From)
+	case Range:
+		text = strconv.Itoa(t.From) + "-" + strconv.Itoa(t.To)
+	case Last:
+		switch {
+		case l == weekdayField:
+			return strconv.Itoa(t.From) + "L"
+		case t.Offset > 0:
+			return "L-" + strconv.Itoa(t.Offset)
+		}
+		return "L"
+	case LastWeekday:
+		return "LW"
+	case NearestWeekday:
+		return strconv.Itoa(t.From) + "W"
+	case Nth:
+		return strconv.Itoa(t.From) + "#" + strconv.Itoa(t.N)
+	default:
+		return fmt.Sprintf("invalid(%d)", t.Kind)
+	}
+
+	if t.Step > 0 {
+		text += "/" + strconv.Itoa(t.Step)
+	}
+	return text
+}
@@ -0,0 +1,158 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * * *", "* * * * *"},
+		{"*/15 9-17 * * MON-FRI", "*/15 9-17 * * 1-5"},
+		{"0 9 1,15 * *", "0 9 1,15 * *"},
+		{"5/10 0 * jan,jul *", "5/10 0 * 1,7 *"},
+		{"0 0 L * *", "0 0 L * *"},
+		{"0 0 L-3 * *", "0 0 L-3 * *"},
+		{"0 0 LW * *", "0 0 LW * *"},
+		{"0 0 15W * *", "0 0 15W * *"},
+		{"0 17 * * 5L", "0 17 * * 5L"},
+		{"0 10 * * 2#2", "0 10 * * 2#2"},
+		{"0 0 1-10/3 * 7", "0 0 1-10/3 * 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if err := s.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	s, err := Parse("*/5 9-17/2 L-2,15W * 1#3,5L")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Schedule{
+		Minutes:  Field{{Kind: Any, Step: 5}},
+		Hours:    Field{{Kind: Range, From: 9, To: 17, Step: 2}},
+		Days:     Field{{Kind: Last, Offset: 2}, {Kind: NearestWeekday, From: 15}},
+		Weekdays: Field{{Kind: Nth, From: 1, N: 3}, {Kind: Last, From: 5}},
+	}
+	if !reflect.DeepEqual(*s, want) {
+		t.Errorf("Parse() = %+v, want %+v", *s, want)
+	}
+	if !s.Months.IsAny() || s.Minutes.IsAny() {
+		t.Error("IsAny() misreports the fields")
+	}
+}
+
+func TestModify(t *testing.T) {
+	s, err := Parse("0 9 * * 1-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shifted := s.Clone()
+	shifted.SetTime(10, 30)
+	shifted.SetField(cron.DayOfWeek, Values(1, 3))
+
+	if got := shifted.String(); got != "30 10 * * 1,3" {
+		t.Errorf("modified schedule = %q", got)
+	}
+	if got := s.String(); got != "0 9 * * 1-5" {
+		t.Errorf("original schedule changed to %q", got)
+	}
+
+	hours, ok := shifted.Field(cron.Hour).Ints()
+	if !ok || !reflect.DeepEqual(hours, []int{10}) {
+		t.Errorf("Ints() = %v, %v", hours, ok)
+	}
+	if _, ok := s.Weekdays.Ints(); ok {
+		t.Error("Ints() accepted a range")
+	}
+}
+
+func TestRender(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Schedule{Minutes: Values(0), Hours: Steps(2), Weekdays: Between(1, 5), Location: amsterdam}
+	got, err := s.Render(cron.Standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "CRON_TZ=Europe/Amsterdam 0 */2 * * 1-5"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	if _, err := (&Schedule{Hours: Values(24)}).Render(cron.Standard); err == nil {
+		t.Error("Render() accepted hour 24")
+	}
+}
+
+func TestSecondsAndYears(t *testing.T) {
+	s := &Schedule{Seconds: Values(0), Minutes: Values(0), Hours: Values(3), Years: Values(2027)}
+	if got := s.String(); got != "0 0 3 * * * 2027" {
+		t.Errorf("String() = %q", got)
+	}
+	if _, err := s.Render(cron.Standard); err == nil {
+		t.Error("Render() wrote a year in the standard dialect")
+	}
+
+	s.Years = nil
+	if got, err := s.Render(cron.Standard); err != nil || got != "0 3 * * *" {
+		t.Errorf("Render() = %q, %v for second 0", got, err)
+	}
+
+	s.Seconds = Values(30)
+	if _, err := s.Render(cron.Standard); err == nil {
+		t.Error("Render() wrote second 30 in the standard dialect")
+	}
+	if got, err := s.Render(cron.StandardSeconds); err != nil || got != "30 0 3 * * *" {
+		t.Errorf("Render() = %q, %v with seconds", got, err)
+	}
+
+	// Years without seconds still write the seconds field, and read back the same
+	for _, expr := range []string{"0 0 9 * * ? 2030", "0 0 9 1 1 * 2030-2032/2"} {
+		s, err := Parse(expr)
+		if err == nil {
+			var again *Schedule
+			again, err = Parse(s.String())
+			if err == nil && !reflect.DeepEqual(again, s) {
+				t.Errorf("Parse(%q) = %+v, want %+v", s.String(), again, s)
+			}
+		}
+		if err != nil {
+			t.Errorf("round trip of %q: %v", expr, err)
+		}
+	}
+	if got := (&Schedule{Hours: Values(9), Years: Values(2030)}).String(); got != "0 * 9 * * * 2030" {
+		t.Errorf("String() = %q", got)
+	}
+
+	parsed, err := Parse("*/10 0 3 * * * 2027-2030")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed.Seconds, Steps(10)) || !reflect.DeepEqual(parsed.Years, Between(2027, 2030)) {
+		t.Errorf("Parse() = %+v", *parsed)
+	}
+}
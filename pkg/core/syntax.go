@@ -0,0 +1,98 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	R "github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+// Syntax tells how the input of a conversion was written
+type Syntax string
+
+const (
+	// SyntaxNatural is natural language matched by a rule
+	SyntaxNatural Syntax = "natural"
+	// SyntaxCron is a 5-, 6- or 7-field cron expression
+	SyntaxCron Syntax = "cron"
+	// SyntaxMacro is a cron macro, such as @daily, @every 1h30m or @reboot
+	SyntaxMacro Syntax = "macro"
+)
+
+var (
+	syntaxPrefix = regexp.MustCompile(`^(?:CRON_TZ|TZ)=\S+\s+`)
+	// cronToken matches a field made of cron symbols, numbers and the
+	// three-letter month and weekday names only
+	cronToken = regexp.MustCompile(`^(?i:[0-9*?/,#LW-]|jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec|sun|mon|tue|wed|thu|fri|sat)+$`)
+)
+
+// machineQuality is the quality of inputs already written in cron syntax,
+// which are read in full
+var machineQuality = R.MatchQuality{Score: 1, Coverage: 1, Specificity: 1}
+
+// DetectSyntax tells whether an input is a macro, a cron expression or natural
+// text. Inputs shaped like cron expressions are detected even when they are
+// invalid, so they fail with the parser's error rather than as unmatched text.
+// Layers on top of CronScribe use it to keep such inputs away from slower engines.
+func DetectSyntax(expression string) Syntax {
+	text := strings.TrimSpace(expression)
+	text = text[len(syntaxPrefix.FindString(text)):]
+
+	if strings.HasPrefix(text, "@") {
+		return SyntaxMacro
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) < 5 || len(fields) > 7 {
+		return SyntaxNatural
+	}
+	for _, field := range fields {
+		if !cronToken.MatchString(field) {
+			return SyntaxNatural
+		}
+	}
+	return SyntaxCron
+}
+
+// convertSyntax normalizes an input already written in cron syntax. Calendar
+// schedules are parsed and rendered in the configured dialect like converted
+// text, @every and @reboot are passed through.
+func (c *CronScribe) convertSyntax(expression string, syntax Syntax) (*Result, error) {
+	text := strings.TrimSpace(expression)
+	result := &Result{
+		Syntax:  syntax,
+		Quality: machineQuality,
+		policy:  c.dstPolicy,
+	}
+
+	switch {
+	case cron.IsReboot(text):
+		result.Expression = cron.Reboot
+		return result, nil
+	case strings.HasPrefix(strings.ToLower(text), "@every"):
+		interval, err := cron.ParseInterval(text)
+		if err != nil {
+			return nil, err
+		}
+		result.Interval = interval
+		result.Expression = cron.FormatInterval(interval)
+		return result, nil
+	}
+
+	expr, err := cron.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.applyLocation(expr, expr.Location, result); err != nil {
+		return nil, err
+	}
+
+	result.schedule = &cron.Expression{Fields: expr.Fields, Second: expr.Second, Year: expr.Year, Location: result.Location}
+
+	result.Expression, err = c.dialect.Render(expr)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+func TestConvertCronSyntax(t *testing.T) {
+	tests := []struct {
+		input   string
+		dialect cron.Dialect
+		want    string
+		syntax  Syntax
+	}{
+		{input: "0 9 * * 1", want: "0 9 * * 1", syntax: SyntaxCron},
+		{input: "0 9 ? * MON-FRI", want: "0 9 * * 1-5", syntax: SyntaxCron},
+		{input: "0 30 9 * * *", want: "30 9 * * *", syntax: SyntaxCron},
+		{input: "15 30 9 * * *", dialect: cron.StandardSeconds, want: "15 30 9 * * *", syntax: SyntaxCron},
+		{input: "0 0 9 1 1 ? 2030", dialect: cron.Dialect{Name: "quartz", Seconds: true, Years: true}, want: "0 0 9 1 1 * 2030", syntax: SyntaxCron},
+		{input: "CRON_TZ=Europe/Amsterdam 0 9 * * *", want: "CRON_TZ=Europe/Amsterdam 0 9 * * *", syntax: SyntaxCron},
+		{input: "@daily", want: "0 0 * * *", syntax: SyntaxMacro},
+		{input: " @Yearly ", want: "0 0 1 1 *", syntax: SyntaxMacro},
+		{input: "@weekly", dialect: cron.StandardSeconds, want: "0 0 0 * * 0", syntax: SyntaxMacro},
+		{input: "@reboot", want: "@reboot", syntax: SyntaxMacro},
+		{input: "@every 90m", want: "@every 1h30m", syntax: SyntaxMacro},
+		{input: "@every 2h0m", want: "@every 2h", syntax: SyntaxMacro},
+		{input: "@every 3600s", want: "@every 1h", syntax: SyntaxMacro},
+		{input: "@every 1h0m30s", want: "@every 1h30s", syntax: SyntaxMacro},
+		{input: "@every 45s", want: "@every 45s", syntax: SyntaxMacro},
+		{input: "every day at 9am", want: "0 9 * * *", syntax: SyntaxNatural},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var options []Option
+			if tt.dialect.Name != "" {
+				options = append(options, WithDialect(tt.dialect))
+			}
+			c, err := New("./rules", options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := c.ConvertResult(tt.input)
+			if err != nil {
+				t.Fatalf("ConvertResult(%q) error = %v", tt.input, err)
+			}
+			if result.Expression != tt.want {
+				t.Errorf("Expression = %q, want %q", result.Expression, tt.want)
+			}
+			if result.Syntax != tt.syntax {
+				t.Errorf("Syntax = %q, want %q", result.Syntax, tt.syntax)
+			}
+			if tt.syntax != SyntaxNatural && result.Quality.Score != 1 {
+				t.Errorf("Quality = %+v, want full quality", result.Quality)
+			}
+		})
+	}
+}
+
+func TestConvertInvalidCronSyntax(t *testing.T) {
+	c, err := New("./rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"0 25 * * *", "0 9 * * 8", "@fortnightly", "@every soon", "15 0 9 * * *"} {
+		if _, err := c.Convert(input); err == nil {
+			t.Errorf("Convert(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestConvertCronSyntaxDescription(t *testing.T) {
+	c, err := New("./rules", WithDescription(), WithTargetLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC) }
+
+	result, err := c.ConvertResult("CRON_TZ=Europe/Amsterdam 0 9 * * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expression != "0 8 * * 1" {
+		t.Errorf("Expression = %q, want %q", result.Expression, "0 8 * * 1")
+	}
+	if result.Description != "every monday at 8am UTC" {
+		t.Errorf("Description = %q", result.Description)
+	}
+
+	natural, err := c.ConvertResult("every day at 9am")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if natural.Description != "every day at 9am" {
+		t.Errorf("Description = %q", natural.Description)
+	}
+
+	interval, err := c.ConvertResult("@every 1h30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interval.Description != "" {
+		t.Errorf("Description = %q, want none", interval.Description)
+	}
+}
+
+func TestConvertIntervalNext(t *testing.T) {
+	c, err := New("./rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.ConvertResult("@every 1h30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	after := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	want := after.Add(90 * time.Minute)
+	if got := decoded.Next(after); !got.Equal(want) {
+		t.Errorf("Next() = %s, want %s", got, want)
+	}
+	if decoded.Syntax != SyntaxMacro {
+		t.Errorf("Syntax = %q, want %q", decoded.Syntax, SyntaxMacro)
+	}
+
+	reboot, err := c.ConvertResult("@reboot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reboot.Next(after).IsZero() {
+		t.Error("Next() of @reboot is not zero")
+	}
+}
+
+func TestDetectSyntax(t *testing.T) {
+	tests := map[string]Syntax{
+		"0 9 * * 1":              SyntaxCron,
+		"*/5 * * * * *":          SyntaxCron,
+		"0 0 12 ? * WED 2027":    SyntaxCron,
+		"TZ=UTC 0 9 * * mon":     SyntaxCron,
+		"@hourly":                SyntaxMacro,
+		"CRON_TZ=UTC @daily":     SyntaxMacro,
+		"every monday at 9am":    SyntaxNatural,
+		"on mon and fri at 9 am": SyntaxNatural,
+		"каждый день в 9 утра":   SyntaxNatural,
+		"0 9 * *":                SyntaxNatural,
+		"1 2 3 4 5 6 7 8":        SyntaxNatural,
+	}
+	for input, want := range tests {
+		if got := DetectSyntax(input); got != want {
+			t.Errorf("DetectSyntax(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+)
+
+var ianaZone = regexp.MustCompile(`(?:^|\s)([A-Za-z]+(?:/[A-Za-z0-9_+-]+)+)(?:\s|$)`)
+
+// detectLocation finds an IANA zone name or a known time zone phrase in the
+// expression. It returns the location and the expression without the zone, or
+// a nil location when the expression names no zone.
+func detectLocation(expression string, phrases map[string]string) (*time.Location, string, error) {
+	if m := ianaZone.FindStringSubmatchIndex(expression); m != nil {
+		name := expression[m[2]:m[3]]
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc, strings.TrimSpace(expression[:m[2]] + expression[m[3]:]), nil
+		}
+	}
+
+	// Try longer phrases first, so "по utc" wins over "utc"
+	keys := make([]string, 0, len(phrases))
+	for phrase := range phrases {
+		keys = append(keys, phrase)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	lower := strings.ToLower(expression)
+	for _, phrase := range keys {
+		start, end, ok := findPhrase(lower, phrase)
+		if !ok {
+			continue
+		}
+
+		loc, err := time.LoadLocation(phrases[phrase])
+		if err != nil {
+			return nil, "", fmt.Errorf("unknown time zone %s: %w", phrases[phrase], err)
+		}
+		return loc, strings.TrimSpace(expression[:start] + expression[end:]), nil
+	}
+
+	return nil, expression, nil
+}
+
+// findPhrase finds a phrase that is not part of a longer word
+func findPhrase(text, phrase string) (int, int, bool) {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], phrase)
+		if i < 0 {
+			return 0, 0, false
+		}
+
+		start := offset + i
+		end := start + len(phrase)
+		if isWordBoundary(text, start, true) && isWordBoundary(text, end, false) {
+			return start, end, true
+		}
+		offset = start + 1
+	}
+	return 0, 0, false
+}
+
+func isWordBoundary(text string, index int, before bool) bool {
+	var r rune
+	if before {
+		r, _ = utf8.DecodeLastRuneInString(text[:index])
+	} else {
+		r, _ = utf8.DecodeRuneInString(text[index:])
+	}
+	return r == utf8.RuneError || (!unicode.IsLetter(r) && !unicode.IsDigit(r))
+}
+
+// applyLocation attaches the time zone to the expression, or moves the
+// expression into the target zone when one is configured
+func (c *CronScribe) applyLocation(expr *cron.Expression, loc *time.Location, result *Result) error {
+	if loc == nil {
+		loc = c.location
+	}
+	result.Location = loc
+	if loc == nil {
+		return nil
+	}
+
+	expr.Location = loc
+	now := c.now()
+	c.addDSTWarnings(expr, now, result)
+
+	if c.targetLocation == nil {
+		return nil
+	}
+
+	shifted, days, err := expr.Shift(c.targetLocation, now)
+	if err != nil {
+		return err
+	}
+
+	if days != 0 {
+		result.addWarning(WarningDayShift, fmt.Sprintf(
+			"schedule moved by %d day(s) when shifted from %s to %s", days, loc, c.targetLocation))
+	}
+	if offsetVaries(loc, c.targetLocation, now.Year()) {
+		result.addWarning(WarningOffsetVaries, fmt.Sprintf(
+			"offset between %s and %s changes during the year, the schedule is exact only while it matches %s",
+			loc, c.targetLocation, now.Format("2006-01-02")))
+	}
+
+	// The target zone is where the scheduler runs, so it is not written out
+	*expr = *shifted
+	expr.Location = nil
+	result.Location = c.targetLocation
+	return nil
+}
+
+// offsetVaries reports whether the difference between two zones differs
+// between winter and summer of the given year
+func offsetVaries(a, b *time.Location, year int) bool {
+	diff := func(t time.Time) int {
+		_, offA := t.In(a).Zone()
+		_, offB := t.In(b).Zone()
+		return offA - offB
+	}
+
+	winter := time.Date(year, time.January, 1, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(year, time.July, 1, 12, 0, 0, 0, time.UTC)
+	return diff(winter) != diff(summer)
+}
+
+// addDSTWarnings warns about scheduled local times that daylight saving
+// transitions within the next year skip or repeat
+func (c *CronScribe) addDSTWarnings(expr *cron.Expression, now time.Time, result *Result) {
+	conflicts := expr.DSTConflicts(now, 366*24*time.Hour)
+
+	for _, kind := range []cron.DSTKind{cron.DSTGap, cron.DSTOverlap} {
+		var first *cron.DSTConflict
+		count := 0
+		for i := range conflicts {
+			if conflicts[i].Kind == kind {
+				if first == nil {
+					first = &conflicts[i]
+				}
+				count++
+			}
+		}
+		if first == nil {
+			continue
+		}
+
+		local := first.Local.Format("2006-01-02 15:04")
+		behaviour := fmt.Sprintf("with policy %s %s", c.dstPolicy, dstBehaviour(kind, c.dstPolicy))
+		if c.targetLocation != nil {
+			behaviour = fmt.Sprintf("the schedule shifted to %s ignores the transition", c.targetLocation)
+		}
+
+		switch kind {
+		case cron.DSTGap:
+			result.addWarning(WarningDSTGap, fmt.Sprintf(
+				"%d scheduled local time(s) do not exist in %s, first %s; %s",
+				count, expr.Location, local, behaviour))
+		case cron.DSTOverlap:
+			result.addWarning(WarningDSTOverlap, fmt.Sprintf(
+				"%d scheduled local time(s) happen twice in %s, first %s; %s",
+				count, expr.Location, local, behaviour))
+		}
+	}
+}
+
+// dstBehaviour describes what the policy does with times inside a transition
+func dstBehaviour(kind cron.DSTKind, policy cron.DSTPolicy) string {
+	switch {
+	case kind == cron.DSTGap && policy == cron.DSTSkip:
+		return "they do not fire"
+	case kind == cron.DSTGap:
+		return "they fire when clocks move forward"
+	case policy == cron.DSTRunTwice:
+		return "they fire twice"
+	default:
+		return "they fire once"
+	}
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/flaticols/cronscribe/pkg/core/rules"
+)
+
+func TestTimezoneConversion(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	winter := func() time.Time { return time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		options  []Option
+		input    string
+		want     string
+		warnings []WarningCode
+	}{
+		{
+			name:  "no zone",
+			input: "every day at 9am",
+			want:  "0 9 * * *",
+		},
+		{
+			name:  "zone in text",
+			input: "every day at 9am Europe/Amsterdam",
+			want:  "CRON_TZ=Europe/Amsterdam 0 9 * * *",
+		},
+		{
+			name:    "default location",
+			options: []Option{WithLocation(amsterdam)},
+			input:   "every monday at 9am",
+			want:    "CRON_TZ=Europe/Amsterdam 0 9 * * 1",
+		},
+		{
+			name:     "shift to UTC",
+			options:  []Option{WithTargetLocation(time.UTC)},
+			input:    "every day at 9am Europe/Amsterdam",
+			want:     "0 8 * * *",
+			warnings: []WarningCode{WarningOffsetVaries},
+		},
+		{
+			name:     "shift across midnight",
+			options:  []Option{WithLocation(amsterdam), WithTargetLocation(time.UTC)},
+			input:    "every monday at 12:30am",
+			want:     "30 23 * * 0",
+			warnings: []WarningCode{WarningDayShift, WarningOffsetVaries},
+		},
+		{
+			name:    "phrase",
+			options: []Option{WithTargetLocation(time.UTC)},
+			input:   "every day at 3pm utc",
+			want:    "0 15 * * *",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New("./rules", tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.now = winter
+
+			result, err := c.ConvertResult(tt.input)
+			if err != nil {
+				t.Fatalf("ConvertResult(%q) error = %v", tt.input, err)
+			}
+			if result.Expression != tt.want {
+				t.Errorf("Expression = %q, want %q", result.Expression, tt.want)
+			}
+
+			if len(result.Warnings) != len(tt.warnings) {
+				t.Fatalf("Warnings = %+v, want %v", result.Warnings, tt.warnings)
+			}
+			for i, code := range tt.warnings {
+				if result.Warnings[i].Code != code {
+					t.Errorf("Warnings[%d] = %s, want %s", i, result.Warnings[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestTimezoneRussianPhrase(t *testing.T) {
+	c, err := New("./rules", WithTargetLocation(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetLanguage("ru"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Convert("каждый день в 9 утра по москве")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "0 6 * * *" {
+		t.Errorf("Convert() = %q, want %q", got, "0 6 * * *")
+	}
+}
+
+func TestResultSchedule(t *testing.T) {
+	c, err := New("./rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.ConvertResult("every day at 9am Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := result.Schedule()
+	if s == nil || s.Location == nil || s.Location.String() != "Europe/Amsterdam" {
+		t.Fatalf("Schedule() = %+v", s)
+	}
+
+	s.SetTime(10, 0)
+	got, err := s.Render(cron.Standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "CRON_TZ=Europe/Amsterdam 0 10 * * *" {
+		t.Errorf("shifted schedule = %q", got)
+	}
+	if result.Expression != "CRON_TZ=Europe/Amsterdam 0 9 * * *" {
+		t.Errorf("result changed to %q", result.Expression)
+	}
+}
+
+func TestResultScheduleSecondsAndYears(t *testing.T) {
+	c, err := New("./rules", WithDialect(cron.Dialect{Name: "quartz", Seconds: true, Years: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	launch, err := rules.NewRules("en").Add(
+		rules.NewRule("launch").Pattern(`^at launch$`).Format("30 0 9 1 1 * 2030"),
+	).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddRules(launch); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.ConvertResult("at launch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expression != "30 0 9 1 1 * 2030" {
+		t.Errorf("Expression = %q", result.Expression)
+	}
+
+	after := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	want := time.Date(2030, time.January, 1, 9, 0, 30, 0, time.UTC)
+	if got := result.Next(after); !got.Equal(want) {
+		t.Errorf("Next() = %s, want %s", got, want)
+	}
+
+	s := result.Schedule()
+	if s == nil || s.String() != "30 0 9 1 1 * 2030" {
+		t.Errorf("Schedule() = %v", s)
+	}
+}
+
+func TestDSTWarnings(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New("./rules", WithLocation(amsterdam), WithDSTPolicy(cron.DSTSkip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC) }
+
+	result, err := c.ConvertResult("every day at 2:30am")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Warnings) != 2 || result.Warnings[0].Code != WarningDSTGap || result.Warnings[1].Code != WarningDSTOverlap {
+		t.Fatalf("Warnings = %+v, want dst_gap and dst_overlap", result.Warnings)
+	}
+
+	next := result.Next(time.Date(2025, time.March, 29, 12, 0, 0, 0, amsterdam))
+	if want := time.Date(2025, time.March, 31, 2, 30, 0, 0, amsterdam); !next.Equal(want) {
+		t.Errorf("Next() = %s, want %s", next, want)
+	}
+}
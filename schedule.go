@@ -0,0 +1,205 @@
+package cronscribe
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/flaticols/cronscribe/pkg/core/schedule"
+	"gopkg.in/yaml.v3"
+)
+
+// scheduleConverter converts the natural text of Schedule values
+var scheduleConverter atomic.Pointer[CronScribe]
+
+// SetScheduleConverter sets the converter Schedule values read natural text
+// with, in its current language. Without one, Schedule values accept cron
+// expressions only.
+func SetScheduleConverter(c *CronScribe) {
+	scheduleConverter.Store(c)
+}
+
+// Schedule is a validated schedule for configuration files, flags and
+// database columns. It is written either as natural text, such as "every day
+// at 3am", or as a cron expression, and keeps the original text so it encodes
+// back the way it was written. The zero value is an unset schedule.
+type Schedule struct {
+	text       string
+	expression string
+	cron       *cron.Expression
+	result     *core.Result
+}
+
+// ParseSchedule reads a schedule with the converter set by SetScheduleConverter
+func ParseSchedule(text string) (Schedule, error) {
+	return scheduleConverter.Load().ParseSchedule(text)
+}
+
+// ParseSchedule reads a schedule written as a cron expression or as natural
+// text in the converter's language. It may be called on a nil CronScribe,
+// which accepts cron expressions only and writes them in the standard dialect.
+func (c *CronScribe) ParseSchedule(text string) (Schedule, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Schedule{}, nil
+	}
+
+	if c == nil {
+		expr, err := cron.Parse(text)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid schedule %q: %w, and no converter is set for natural text", text, err)
+		}
+		expression, err := cron.Standard.Render(expr)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid schedule %q: %w", text, err)
+		}
+		return Schedule{text: text, expression: expression, cron: expr}, nil
+	}
+
+	// The converter reads cron expressions too, applying its time zones,
+	// dialect and DST policy like it does to text
+	result, err := c.ConvertResult(text)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid schedule %q: %w", text, err)
+	}
+	s := Schedule{text: text, expression: result.Expression, result: result}
+	if typed := result.Schedule(); typed != nil {
+		s.cron, _ = typed.Expression()
+	}
+	return s, nil
+}
+
+// IsZero reports whether the schedule is unset
+func (s Schedule) IsZero() bool {
+	return s.text == ""
+}
+
+// Text returns the schedule as it was written
+func (s Schedule) Text() string {
+	return s.text
+}
+
+// Expression returns the cron expression, in the converter's dialect
+func (s Schedule) Expression() string {
+	return s.expression
+}
+
+// Schedule returns the typed fields of the schedule, nil when unset
+func (s Schedule) Schedule() *schedule.Schedule {
+	if s.cron == nil {
+		return nil
+	}
+	typed, err := schedule.FromExpression(s.cron)
+	if err != nil {
+		return nil
+	}
+	return typed
+}
+
+// Next returns the next time after the given instant the schedule fires, or
+// the zero time when it is unset or never fires
+func (s Schedule) Next(after time.Time) time.Time {
+	switch {
+	case s.result != nil:
+		return s.result.Next(after)
+	case s.cron != nil:
+		return s.cron.Next(after, cron.DSTRunOnce)
+	}
+	return time.Time{}
+}
+
+// String returns the schedule as it was written, for flag.Value
+func (s Schedule) String() string {
+	return s.text
+}
+
+// Set parses the schedule, for flag.Value
+func (s *Schedule) Set(text string) error {
+	parsed, err := ParseSchedule(text)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Schedule) MarshalText() ([]byte, error) {
+	return []byte(s.text), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Schedule) UnmarshalText(text []byte) error {
+	return s.Set(string(text))
+}
+
+// MarshalJSON encodes the schedule as the string it was written as, or null when unset
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	if s.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(s.text)
+}
+
+// UnmarshalJSON decodes a string, or null for an unset schedule
+func (s *Schedule) UnmarshalJSON(data []byte) error {
+	var text *string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("schedule must be a string: %w", err)
+	}
+	if text == nil {
+		*s = Schedule{}
+		return nil
+	}
+	return s.Set(*text)
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (s Schedule) MarshalYAML() (any, error) {
+	if s.IsZero() {
+		return nil, nil
+	}
+	return s.text, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (s *Schedule) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: schedule must be a string", node.Line)
+	}
+	if node.Tag == "!!null" {
+		*s = Schedule{}
+		return nil
+	}
+	if err := s.Set(node.Value); err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	return nil
+}
+
+// Scan implements sql.Scanner for text columns, NULL being an unset schedule
+func (s *Schedule) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*s = Schedule{}
+		return nil
+	case string:
+		return s.Set(v)
+	case []byte:
+		return s.Set(string(v))
+	}
+	return fmt.Errorf("cannot scan %T into a schedule", src)
+}
+
+// Value implements driver.Valuer, storing the text it was written as, or NULL when unset
+func (s Schedule) Value() (driver.Value, error) {
+	if s.IsZero() {
+		return nil, nil
+	}
+	return s.text, nil
+}
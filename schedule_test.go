@@ -0,0 +1,157 @@
+package cronscribe
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/flaticols/cronscribe/pkg/core"
+	"github.com/flaticols/cronscribe/pkg/core/cron"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func useScheduleConverter(t *testing.T) {
+	t.Helper()
+	sc, err := New("./pkg/core/rules")
+	require.NoError(t, err)
+	SetScheduleConverter(sc)
+	t.Cleanup(func() { SetScheduleConverter(nil) })
+}
+
+type serviceConfig struct {
+	Backup  Schedule `json:"backup_schedule" yaml:"backup_schedule"`
+	Cleanup Schedule `json:"cleanup_schedule" yaml:"cleanup_schedule"`
+	Report  Schedule `json:"report_schedule,omitempty" yaml:"report_schedule,omitempty"`
+}
+
+func TestScheduleYAML(t *testing.T) {
+	useScheduleConverter(t)
+
+	var cfg serviceConfig
+	err := yaml.Unmarshal([]byte("backup_schedule: every day at 3am\ncleanup_schedule: \"*/15 * * * *\"\n"), &cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, "every day at 3am", cfg.Backup.Text())
+	require.Equal(t, "0 3 * * *", cfg.Backup.Expression())
+	require.Equal(t, "*/15 * * * *", cfg.Cleanup.Expression())
+	require.True(t, cfg.Report.IsZero())
+
+	next := cfg.Backup.Next(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	require.Equal(t, 3, next.Hour())
+
+	data, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "backup_schedule: every day at 3am\ncleanup_schedule: '*/15 * * * *'\n", string(data))
+
+	err = yaml.Unmarshal([]byte("backup_schedule: whenever you feel like it\n"), &cfg)
+	require.ErrorContains(t, err, "line 1")
+	err = yaml.Unmarshal([]byte("backup_schedule: [daily]\n"), &cfg)
+	require.Error(t, err)
+}
+
+func TestScheduleJSON(t *testing.T) {
+	useScheduleConverter(t)
+
+	var cfg serviceConfig
+	err := json.Unmarshal([]byte(`{"backup_schedule": "every monday at 9am", "cleanup_schedule": null}`), &cfg)
+	require.NoError(t, err)
+	require.Equal(t, "0 9 * * 1", cfg.Backup.Expression())
+	require.True(t, cfg.Cleanup.IsZero())
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"backup_schedule": "every monday at 9am", "cleanup_schedule": null, "report_schedule": null}`, string(data))
+
+	require.Error(t, json.Unmarshal([]byte(`{"backup_schedule": 5}`), &cfg))
+}
+
+func TestScheduleText(t *testing.T) {
+	useScheduleConverter(t)
+
+	var s Schedule
+	require.NoError(t, s.UnmarshalText([]byte("  0 9 * * MON  ")))
+	require.Equal(t, "0 9 * * MON", s.Text())
+	require.Equal(t, "0 9 * * 1", s.Expression())
+	require.Equal(t, "0 9 * * 1", s.Schedule().String())
+
+	text, err := s.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "0 9 * * MON", string(text))
+}
+
+func TestScheduleFlag(t *testing.T) {
+	useScheduleConverter(t)
+
+	var s Schedule
+	flags := flag.NewFlagSet("service", flag.ContinueOnError)
+	flags.Var(&s, "schedule", "when to run")
+
+	require.NoError(t, flags.Parse([]string{"-schedule", "every 15 minutes"}))
+	require.Equal(t, "*/15 * * * *", s.Expression())
+	require.Equal(t, "every 15 minutes", flags.Lookup("schedule").Value.String())
+}
+
+func TestScheduleSQL(t *testing.T) {
+	useScheduleConverter(t)
+
+	var s Schedule
+	require.NoError(t, s.Scan([]byte("every hour")))
+	require.Equal(t, "0 * * * *", s.Expression())
+
+	value, err := s.Value()
+	require.NoError(t, err)
+	require.Equal(t, "every hour", value)
+
+	require.NoError(t, s.Scan(nil))
+	require.True(t, s.IsZero())
+	value, err = s.Value()
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	require.Error(t, s.Scan(42))
+}
+
+func TestScheduleWithoutConverter(t *testing.T) {
+	s, err := ParseSchedule("CRON_TZ=Europe/Amsterdam 30 2 * * *")
+	require.NoError(t, err)
+	require.Equal(t, "CRON_TZ=Europe/Amsterdam 30 2 * * *", s.Expression())
+	require.Equal(t, "Europe/Amsterdam", s.Schedule().Location.String())
+
+	_, err = ParseSchedule("every day at 3am")
+	require.ErrorContains(t, err, "no converter")
+}
+
+func TestScheduleCronUsesConverterSettings(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	sc, err := New("./pkg/core/rules", core.WithLocation(amsterdam), core.WithDSTPolicy(cron.DSTSkip))
+	require.NoError(t, err)
+
+	text, err := sc.ParseSchedule("every day at 3am")
+	require.NoError(t, err)
+	raw, err := sc.ParseSchedule("0 3 * * *")
+	require.NoError(t, err)
+	require.Equal(t, "CRON_TZ=Europe/Amsterdam 0 3 * * *", text.Expression())
+	require.Equal(t, text.Expression(), raw.Expression())
+
+	// 2:30 does not exist on the day clocks move forward, the skip policy waits a day
+	nightly, err := sc.ParseSchedule("30 2 * * *")
+	require.NoError(t, err)
+	after := time.Date(2025, time.March, 30, 0, 0, 0, 0, amsterdam)
+	require.Equal(t, time.Date(2025, time.March, 31, 2, 30, 0, 0, amsterdam), nightly.Next(after).In(amsterdam))
+
+	shifting, err := New("./pkg/core/rules", core.WithTargetLocation(newYork))
+	require.NoError(t, err)
+
+	converted, err := shifting.ConvertResult("CRON_TZ=Europe/Amsterdam 0 3 * * *")
+	require.NoError(t, err)
+	s, err := shifting.ParseSchedule("CRON_TZ=Europe/Amsterdam 0 3 * * *")
+	require.NoError(t, err)
+	require.Equal(t, converted.Expression, s.Expression())
+	require.NotContains(t, s.Expression(), "CRON_TZ")
+}